@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var noCache bool
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the local GitHub response cache",
+	Long: "GitHub responses are cached on disk and revalidated with ETag/Last-Modified, " +
+		"so unchanged pages are not downloaded again and do not count against the rate limit.",
+}
+
+var cacheInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show the cache location and size",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := openCache()
+		if err != nil {
+			return err
+		}
+		entries, err := c.Entries()
+		if err != nil {
+			return fmt.Errorf("failed to read cache: %w", err)
+		}
+
+		var size int64
+		for _, e := range entries {
+			size += e.Size
+		}
+		fmt.Printf("Location: %s\n", c.Dir)
+		fmt.Printf("Entries:  %d\n", len(entries))
+		fmt.Printf("Size:     %s\n", formatBytes(size))
+		if len(entries) > 0 {
+			fmt.Printf("Oldest:   %s\n", entries[0].StoredAt.Local().Format(time.DateTime))
+			fmt.Printf("Newest:   %s\n", entries[len(entries)-1].StoredAt.Local().Format(time.DateTime))
+		}
+		return nil
+	},
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached responses, oldest first",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := openCache()
+		if err != nil {
+			return err
+		}
+		entries, err := c.Entries()
+		if err != nil {
+			return fmt.Errorf("failed to read cache: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "STORED\tSIZE\tURL")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", e.StoredAt.Local().Format(time.DateTime), formatBytes(e.Size), e.URL)
+		}
+		return w.Flush()
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached responses",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := openCache()
+		if err != nil {
+			return err
+		}
+		n, err := c.Clear()
+		if err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+		fmt.Printf("Removed %d cached responses from %s\n", n, c.Dir)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Don't read or write the local GitHub response cache")
+
+	cacheCmd.AddCommand(cacheInfoCmd, cacheListCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// enableCache turns on the GitHub response cache unless --no-cache is set.
+// A missing cache directory is not worth failing a run over.
+func enableCache() {
+	if noCache {
+		return
+	}
+	if c, err := openCache(); err == nil {
+		github.SetCache(c)
+	}
+}
+
+func openCache() (*github.Cache, error) {
+	if dir := os.Getenv("GITISSUESUM_CACHE_DIR"); dir != "" {
+		return github.NewCache(dir), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return github.NewCache(filepath.Join(dir, "gitissuesum", "github")), nil
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
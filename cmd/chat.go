@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+	"github.com/spf13/cobra"
+)
+
+const chatHelp = `Type a question, or one of:
+  /issues      list the issues cited in the last answer
+  /show N      show issue #N in full
+  /reset       forget the conversation, keeping the issues
+  /save FILE   write the conversation to FILE as Markdown
+  /help        show this help
+  /quit        leave (so does end of input)
+`
+
+var chatCmd = &cobra.Command{
+	Use:   "chat [owner/repo or GitHub/GitLab URL]",
+	Short: "Ask follow-up questions about a repository's issues",
+	Long: "Fetches the issues once and starts an interactive session for asking questions about them, " +
+		"such as which ones block a release. Earlier questions and answers are kept as context for later ones.\n\n" + chatHelp,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, err := singleRepo(args)
+		if err != nil {
+			return err
+		}
+		if err := validateFilter(ref.GitLab); err != nil {
+			return err
+		}
+		params, err := requestParams(cmd)
+		if err != nil {
+			return err
+		}
+		provider, err := newMeter(cmd)
+		if err != nil {
+			return err
+		}
+		defer reportUsage(provider)
+
+		chat, err := summarize.NewChat(cmd.Context(), summarize.Options{
+			Source:          newSource(ref),
+			Provider:        provider,
+			Model:           model,
+			MaxIssues:       maxIssues,
+			Filter:          filter,
+			IncludeComments: includeComments,
+			Params:          params,
+		})
+		if err != nil {
+			return err
+		}
+		logf("Ask about the issues in %s; /help lists the commands.\n", chat.Repo())
+		return runChat(cmd, chat)
+	},
+}
+
+func init() {
+	chatCmd.Flags().BoolVar(&includeComments, "include-comments", false, "Include a digest of each issue's comments in the context")
+	addIssueFlags(chatCmd)
+	rootCmd.AddCommand(chatCmd)
+}
+
+// runChat reads questions and commands from stdin until /quit or the end of
+// input. Answers go to stdout and everything else to stderr, so a file of
+// questions can be piped in and the answers saved.
+func runChat(cmd *cobra.Command, chat *summarize.Chat) error {
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for {
+		logf("> ")
+		if !scanner.Scan() {
+			logf("\n")
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		command, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+		switch command {
+		case "/quit", "/exit":
+			return nil
+		case "/help":
+			logf("%s", chatHelp)
+		case "/reset":
+			chat.Reset()
+			logf("Conversation cleared.\n")
+		case "/issues":
+			refs := chat.Referenced()
+			if len(refs) == 0 {
+				logf("The last answer cites none of the loaded issues.\n")
+			}
+			for _, issue := range refs {
+				fmt.Printf("#%d %s (%s)\n", issue.Number, issue.Title, firstNonEmpty(issue.URL, issue.State))
+			}
+		case "/show":
+			n, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+			if err != nil {
+				logf("Usage: /show N\n")
+				continue
+			}
+			issue, ok := chat.Issue(n)
+			if !ok {
+				logf("Issue #%d is not among the loaded issues.\n", n)
+				continue
+			}
+			if err := chat.WriteIssue(os.Stdout, issue); err != nil {
+				return err
+			}
+		case "/save":
+			if arg == "" {
+				logf("Usage: /save FILE\n")
+				continue
+			}
+			if err := saveTranscript(chat, arg); err != nil {
+				logf("%v\n", err)
+				continue
+			}
+			logf("Saved the conversation to %s.\n", arg)
+		default:
+			if strings.HasPrefix(command, "/") {
+				logf("Unknown command %s.\n%s", command, chatHelp)
+				continue
+			}
+			fmt.Println()
+			_, err := chat.Ask(cmd.Context(), line, func(text string) {
+				fmt.Print(text)
+			})
+			fmt.Println()
+			fmt.Println()
+			if err != nil {
+				// The question wasn't kept, so it can be asked again.
+				logf("Failed to get an answer: %v\n", err)
+			}
+		}
+	}
+}
+
+func saveTranscript(chat *summarize.Chat, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to save the conversation: %w", err)
+	}
+	if err := chat.WriteTranscript(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to save the conversation: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to save the conversation: %w", err)
+	}
+	return nil
+}
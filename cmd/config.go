@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mrphil/gitissuesum/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	profile string
+
+	// cfg is the merged config, loaded before any command runs.
+	cfg = &config.Config{}
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+	Long: "Settings come from, highest precedence first: command-line flags, environment variables " +
+		"($ANTHROPIC_BASE_URL, $OPENAI_BASE_URL, $GITHUB_API_URL), the selected profile, the top-level settings " +
+		"of the config files, and built-in defaults. The repository-local " + config.LocalName + " (found in the " +
+		"current directory or a parent, up to the git root) takes precedence over the user config file, but only " +
+		"the user config file can set provider, base_url and api_url.",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// The summary flags belong to the root command; fill them in too.
+		if err := applyConfig(rootCmd); err != nil {
+			return err
+		}
+
+		if cfg.Profile != "" {
+			fmt.Printf("# profile: %s\n", cfg.Profile)
+		}
+		if len(cfg.Files) == 0 {
+			fmt.Println("# no config files found")
+		}
+		for _, f := range cfg.Files {
+			fmt.Printf("# from %s\n", f)
+		}
+
+		effective := config.Settings{
+			Provider:         providerName,
+			Model:            model,
+			BaseURL:          firstNonEmpty(baseURL, os.Getenv(baseURLEnv())),
+			APIURL:           firstNonEmpty(apiURL, os.Getenv("GITHUB_API_URL")),
+			Output:           output,
+			Template:         templateName,
+			MaxIssues:        maxIssues,
+			MaxInputTokens:   maxInputTokens,
+			MaxCost:          maxCost,
+			System:           systemPrompt,
+			MaxTokens:        maxTokens,
+			MaxContinuations: maxContinuations,
+			StopSequences:    stopSequences,
+			UserID:           userID,
+			PromptCache:      &promptCache,
+			IncludeComments:  &includeComments,
+			Repos:            cfg.Repos,
+			Filter: config.Filter{
+				State:     filter.State,
+				Labels:    filter.Labels,
+				Assignee:  filter.Assignee,
+				Author:    filter.Author,
+				Milestone: filter.Milestone,
+				Since:     since,
+				Sort:      filter.Sort,
+				Direction: filter.Direction,
+			},
+			Prices: cfg.Prices,
+		}
+		if rootCmd.Flags().Changed("temperature") {
+			effective.Temperature = &temperature
+		}
+		if rootCmd.Flags().Changed("top-p") {
+			effective.TopP = &topP
+		}
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(effective); err != nil {
+			return err
+		}
+		return enc.Close()
+	},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Config profile to use (default from $GITISSUESUM_PROFILE or default_profile in the config file)")
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// loadConfig reads the user config file and the repository-local one.
+func loadConfig() (*config.Config, error) {
+	var paths []string
+	userPath, err := config.UserPath()
+	if err == nil {
+		paths = append(paths, userPath)
+	}
+	if dir, err := os.Getwd(); err == nil {
+		if local := config.LocalPath(dir); local != "" {
+			paths = append(paths, local)
+		}
+	}
+	return config.Load(paths, firstNonEmpty(profile, os.Getenv("GITISSUESUM_PROFILE")))
+}
+
+// applyConfig loads the config and uses it for every flag of cmd that was
+// not given on the command line. Settings that also have an environment
+// variable are left alone when the variable is set, so it takes precedence.
+func applyConfig(cmd *cobra.Command) error {
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	cfg = c
+
+	s := cfg.Settings
+	var maxIssuesValue, maxInputTokensValue, maxCostValue, maxTokensValue, maxContinuationsValue, temperatureValue, topPValue, promptCacheValue, includeCommentsValue string
+	if s.MaxIssues != 0 {
+		maxIssuesValue = strconv.Itoa(s.MaxIssues)
+	}
+	if s.MaxInputTokens != 0 {
+		maxInputTokensValue = strconv.Itoa(s.MaxInputTokens)
+	}
+	if s.MaxCost != 0 {
+		maxCostValue = strconv.FormatFloat(s.MaxCost, 'f', -1, 64)
+	}
+	if s.MaxTokens != 0 {
+		maxTokensValue = strconv.Itoa(s.MaxTokens)
+	}
+	if s.MaxContinuations != 0 {
+		maxContinuationsValue = strconv.Itoa(s.MaxContinuations)
+	}
+	if s.Temperature != nil {
+		temperatureValue = strconv.FormatFloat(*s.Temperature, 'f', -1, 64)
+	}
+	if s.TopP != nil {
+		topPValue = strconv.FormatFloat(*s.TopP, 'f', -1, 64)
+	}
+	if s.PromptCache != nil {
+		promptCacheValue = strconv.FormatBool(*s.PromptCache)
+	}
+	if s.IncludeComments != nil {
+		includeCommentsValue = strconv.FormatBool(*s.IncludeComments)
+	}
+
+	values := []struct {
+		flag, value string
+		env         func() string
+	}{
+		// provider first, so base-url checks the right variable.
+		{flag: "provider", value: s.Provider},
+		{flag: "model", value: s.Model},
+		{flag: "base-url", value: s.BaseURL, env: baseURLEnv},
+		{flag: "api-url", value: s.APIURL, env: func() string { return "GITHUB_API_URL" }},
+		{flag: "output", value: s.Output},
+		{flag: "template", value: s.Template},
+		{flag: "max-issues", value: maxIssuesValue},
+		{flag: "max-input-tokens", value: maxInputTokensValue},
+		{flag: "max-cost", value: maxCostValue},
+		{flag: "system", value: s.System},
+		{flag: "max-tokens", value: maxTokensValue},
+		{flag: "max-continuations", value: maxContinuationsValue},
+		{flag: "temperature", value: temperatureValue},
+		{flag: "top-p", value: topPValue},
+		{flag: "user-id", value: s.UserID},
+		{flag: "prompt-cache", value: promptCacheValue},
+		{flag: "include-comments", value: includeCommentsValue},
+		{flag: "state", value: s.Filter.State},
+		{flag: "label", value: strings.Join(s.Filter.Labels, ",")},
+		{flag: "assignee", value: s.Filter.Assignee},
+		{flag: "author", value: s.Filter.Author},
+		{flag: "milestone", value: s.Filter.Milestone},
+		{flag: "since", value: s.Filter.Since},
+		{flag: "sort", value: s.Filter.Sort},
+		{flag: "direction", value: s.Filter.Direction},
+	}
+	for _, v := range values {
+		f := cmd.Flags().Lookup(v.flag)
+		if v.value == "" || f == nil || f.Changed {
+			continue
+		}
+		if v.env != nil && os.Getenv(v.env()) != "" {
+			continue
+		}
+		if err := cmd.Flags().Set(v.flag, v.value); err != nil {
+			return fmt.Errorf("invalid %s in config: %w", v.flag, err)
+		}
+	}
+	// --stop repeats rather than splitting on commas, which stop
+	// sequences may contain.
+	if f := cmd.Flags().Lookup("stop"); f != nil && !f.Changed {
+		for _, stop := range s.StopSequences {
+			if err := cmd.Flags().Set("stop", stop); err != nil {
+				return fmt.Errorf("invalid stop in config: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// baseURLEnv names the environment variable holding the base URL of the
+// selected provider.
+func baseURLEnv() string {
+	if providerName == providerOpenAI {
+		return "OPENAI_BASE_URL"
+	}
+	return "ANTHROPIC_BASE_URL"
+}
+
+// repoArgs returns the repositories named on the command line, or else the
+// configured default repos.
+func repoArgs(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	if len(cfg.Repos) > 0 {
+		return cfg.Repos, nil
+	}
+	return nil, errors.New("no repository given, and the config has no default repos")
+}
+
+// singleRepo is repoArgs for commands that work on one repository.
+func singleRepo(args []string) (repoRef, error) {
+	repos, err := repoArgs(args)
+	if err != nil {
+		return repoRef{}, err
+	}
+	if len(repos) > 1 {
+		return repoRef{}, fmt.Errorf("this command works on one repository, but the config lists %d; name one", len(repos))
+	}
+	return parseRepo(repos[0])
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mrphil/gitissuesum/internal/duplicates"
+	"github.com/mrphil/gitissuesum/internal/summarize"
+	"github.com/spf13/cobra"
+)
+
+var (
+	duplicatesOutput string
+	threshold        float64
+	confirm          bool
+)
+
+var duplicatesCmd = &cobra.Command{
+	Use:   "duplicates [owner/repo or GitHub/GitLab URL]",
+	Short: "Find groups of likely duplicate issues",
+	Long: "Compares issue titles and bodies locally to group likely duplicates and suggest which issue to keep. " +
+		"With --confirm, each group is checked by the model, which drops false matches.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, err := singleRepo(args)
+		if err != nil {
+			return err
+		}
+		if duplicatesOutput != summarize.OutputText && duplicatesOutput != summarize.OutputJSON {
+			return fmt.Errorf("invalid --output %q, expected %q or %q", duplicatesOutput, summarize.OutputText, summarize.OutputJSON)
+		}
+		if threshold <= 0 || threshold > 1 {
+			return fmt.Errorf("invalid --threshold %v, expected a value in (0, 1]", threshold)
+		}
+		if err := validateFilter(ref.GitLab); err != nil {
+			return err
+		}
+
+		var provider *summarize.Meter
+		var params summarize.Params
+		if confirm {
+			if provider, err = newMeter(cmd); err != nil {
+				return err
+			}
+			if params, err = requestParams(cmd); err != nil {
+				return err
+			}
+			defer reportUsage(provider)
+		}
+
+		source := newSource(ref)
+		logf("Fetching issues from %s...\n", source.Repo())
+		issues, err := source.FetchIssues(cmd.Context(), filter, maxIssues)
+		if err != nil {
+			return fmt.Errorf("failed to fetch issues: %w", err)
+		}
+
+		clusters := duplicates.Find(issues, threshold)
+		logf("Found %d candidate clusters among %d issues.\n", len(clusters), len(issues))
+		if confirm && len(clusters) > 0 {
+			clusters, err = duplicates.Confirm(cmd.Context(), summarize.Options{Provider: provider, Model: model, Params: params}, issues, clusters, logf)
+			if err != nil {
+				return fmt.Errorf("failed to confirm duplicates: %w", err)
+			}
+		}
+
+		report := duplicates.Report{
+			Repository: source.Repo(),
+			IssueCount: len(issues),
+			Threshold:  threshold,
+			Confirmed:  confirm,
+			Clusters:   clusters,
+		}
+		if confirm {
+			report.Usage = provider.Report()
+		}
+		if duplicatesOutput == summarize.OutputJSON {
+			return summarize.WriteJSON(report)
+		}
+		return duplicates.WriteText(os.Stdout, report)
+	},
+}
+
+func init() {
+	duplicatesCmd.Flags().StringVarP(&duplicatesOutput, "output", "o", summarize.OutputText, "Output format: text or json")
+	duplicatesCmd.Flags().Float64Var(&threshold, "threshold", 0.4, "Minimum text similarity (0-1) for two issues to be grouped")
+	duplicatesCmd.Flags().BoolVar(&confirm, "confirm", false, "Ask the model to confirm each group and pick the issue to keep")
+	addIssueFlags(duplicatesCmd)
+	rootCmd.AddCommand(duplicatesCmd)
+}
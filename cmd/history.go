@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mrphil/gitissuesum/internal/history"
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+var (
+	sinceLast bool
+	noHistory bool
+)
+
+func init() {
+	rootCmd.Flags().BoolVar(&sinceLast, "since-last", false, "Summarize only what changed since the last saved snapshot of this repo")
+	rootCmd.Flags().BoolVar(&noHistory, "no-history", false, "Don't save a snapshot of this run's issues and summary")
+}
+
+func openHistory() (*history.Store, error) {
+	if dir := os.Getenv("GITISSUESUM_HISTORY_DIR"); dir != "" {
+		return history.NewStore(dir), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate history directory: %w", err)
+	}
+	return history.NewStore(filepath.Join(dir, "gitissuesum", "history")), nil
+}
+
+// historyKey identifies a repository across runs, including its host so
+// that same-named repositories on different instances stay apart.
+func historyKey(ref repoRef) string {
+	return ref.Host + "/" + ref.Owner + "/" + ref.Name
+}
+
+// previousSnapshot loads the snapshot to compare against for --since-last.
+// With no earlier snapshot the run falls back to a full summary.
+func previousSnapshot(store *history.Store, ref repoRef) (*summarize.Snapshot, error) {
+	prev, err := store.Latest(historyKey(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the last snapshot: %w", err)
+	}
+	if prev == nil {
+		logf("No earlier snapshot of %s/%s, writing a full summary instead.\n", ref.Owner, ref.Name)
+		return nil, nil
+	}
+	if prev.State != filter.State {
+		logf("Warning: the last snapshot was of %s issues, this run fetches %s ones.\n", prev.State, filter.State)
+	}
+	return prev, nil
+}
+
+// saveSnapshot records a finished run. A failure is reported but does not
+// fail the run, whose summary has already been written.
+func saveSnapshot(store *history.Store, ref repoRef, snap *summarize.Snapshot) {
+	if err := store.Save(historyKey(ref), snap); err != nil {
+		logf("Warning: failed to save snapshot: %v\n", err)
+	}
+}
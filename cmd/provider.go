@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+	"github.com/mrphil/gitissuesum/internal/openai"
+	"github.com/mrphil/gitissuesum/internal/summarize"
+	"github.com/spf13/cobra"
+)
+
+const (
+	providerAnthropic = "anthropic"
+	providerOpenAI    = "openai"
+)
+
+var (
+	model        string
+	providerName string
+	baseURL      string
+
+	systemPrompt  string
+	maxTokens     int
+	temperature   float64
+	topP          float64
+	stopSequences []string
+	userID        string
+	promptCache   bool
+
+	maxContinuations int
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&model, "model", "claude-sonnet-4-20250514", "Model to use")
+	rootCmd.PersistentFlags().StringVar(&providerName, "provider", providerAnthropic, "LLM provider: anthropic or openai (any OpenAI-compatible chat completions server)")
+	rootCmd.PersistentFlags().StringVar(&baseURL, "base-url", "", "Provider API base URL (default from $ANTHROPIC_BASE_URL or $OPENAI_BASE_URL)")
+	rootCmd.PersistentFlags().StringVar(&systemPrompt, "system", "", "System prompt replacing the built-in one, which casts the model as a maintainer analyzing the issues")
+	rootCmd.PersistentFlags().IntVar(&maxTokens, "max-tokens", 4096, "Most tokens the model may generate per request")
+	rootCmd.PersistentFlags().IntVar(&maxContinuations, "max-continuations", 0, "Times to ask the model to carry on when a reply is cut off at --max-tokens")
+	rootCmd.PersistentFlags().Float64Var(&temperature, "temperature", 0, "Sampling temperature (default the provider's)")
+	rootCmd.PersistentFlags().Float64Var(&topP, "top-p", 0, "Nucleus sampling probability mass (default the provider's)")
+	rootCmd.PersistentFlags().StringArrayVar(&stopSequences, "stop", nil, "Stop generating at this text (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&userID, "user-id", "", "Opaque user ID sent as request metadata")
+	rootCmd.PersistentFlags().BoolVar(&promptCache, "prompt-cache", false, "Cache the issue listing with the provider, so later requests over the same issues cost less")
+}
+
+// requestParams collects the request settings. Temperature and top-p are
+// only sent when set, so the provider's defaults otherwise apply.
+func requestParams(cmd *cobra.Command) (summarize.Params, error) {
+	if maxTokens < 1 {
+		return summarize.Params{}, fmt.Errorf("invalid --max-tokens %d, expected 1 or more", maxTokens)
+	}
+	if maxContinuations < 0 {
+		return summarize.Params{}, fmt.Errorf("invalid --max-continuations %d, expected 0 or more", maxContinuations)
+	}
+	p := summarize.Params{
+		System:           systemPrompt,
+		MaxTokens:        maxTokens,
+		MaxContinuations: maxContinuations,
+		StopSequences:    stopSequences,
+		UserID:           userID,
+		PromptCache:      promptCache,
+	}
+	if cmd.Flags().Changed("temperature") {
+		if temperature < 0 || temperature > 2 {
+			return summarize.Params{}, fmt.Errorf("invalid --temperature %v, expected a value from 0 to 2", temperature)
+		}
+		p.Temperature = &temperature
+	}
+	if cmd.Flags().Changed("top-p") {
+		if topP <= 0 || topP > 1 {
+			return summarize.Params{}, fmt.Errorf("invalid --top-p %v, expected a value in (0, 1]", topP)
+		}
+		p.TopP = &topP
+	}
+	return p, nil
+}
+
+func newProvider(cmd *cobra.Command) (summarize.Provider, error) {
+	switch providerName {
+	case providerAnthropic:
+		apiKey := os.Getenv("ANTHROPIC_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable is required")
+		}
+		client := claude.NewClient(apiKey)
+		client.BaseURL = firstNonEmpty(baseURL, os.Getenv("ANTHROPIC_BASE_URL"))
+		return client, nil
+
+	case providerOpenAI:
+		if !cmd.Flags().Changed("model") {
+			return nil, fmt.Errorf("--model is required with --provider %s", providerOpenAI)
+		}
+		url := firstNonEmpty(baseURL, os.Getenv("OPENAI_BASE_URL"), openai.DefaultBaseURL)
+		return openai.NewClient(url, os.Getenv("OPENAI_API_KEY")), nil
+	}
+	return nil, fmt.Errorf("invalid --provider %q, expected %q or %q", providerName, providerAnthropic, providerOpenAI)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
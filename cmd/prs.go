@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prsOutput string
+	maxPulls  int
+)
+
+var prsCmd = &cobra.Command{
+	Use:   "prs [owner/repo or GitHub URL]",
+	Short: "Summarize the open pull request review queue",
+	Long: "Fetches open pull requests with their draft state, review decision, requested reviewers, size, " +
+		"CI status and time since the last push, and summarizes the review queue, flagging stuck or risky pull requests. " +
+		"Only GitHub repositories are supported.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, err := singleRepo(args)
+		if err != nil {
+			return err
+		}
+		if ref.GitLab {
+			return fmt.Errorf("pull request summaries are only supported for GitHub repositories")
+		}
+		if prsOutput != summarize.OutputText && prsOutput != summarize.OutputJSON {
+			return fmt.Errorf("invalid --output %q, expected %q or %q", prsOutput, summarize.OutputText, summarize.OutputJSON)
+		}
+		if maxPulls < 1 {
+			return fmt.Errorf("invalid --max-prs %d, expected 1 or more", maxPulls)
+		}
+
+		params, err := requestParams(cmd)
+		if err != nil {
+			return err
+		}
+		provider, err := newMeter(cmd)
+		if err != nil {
+			return err
+		}
+		defer reportUsage(provider)
+
+		return summarize.RunPulls(cmd.Context(), summarize.Options{
+			Source:    newSource(ref),
+			Provider:  provider,
+			Model:     model,
+			MaxIssues: maxPulls,
+			Output:    prsOutput,
+			Params:    params,
+		})
+	},
+}
+
+func init() {
+	prsCmd.Flags().StringVarP(&prsOutput, "output", "o", summarize.OutputText, "Output format: text or json (the pull requests and the summary)")
+	prsCmd.Flags().IntVar(&maxPulls, "max-prs", 100, "Maximum number of pull requests to fetch")
+	rootCmd.AddCommand(prsCmd)
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mrphil/gitissuesum/internal/github"
+	"github.com/mrphil/gitissuesum/internal/gitlab"
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+const (
+	dotcomHost = "github.com"
+	gitlabHost = "gitlab.com"
+)
+
+var validRepoName = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+var apiURL string
+
+type repoRef struct {
+	// GitLab is set for GitLab projects, whose Owner is the full namespace
+	// and may include subgroups.
+	GitLab bool
+	// Host is the web host of the instance, used to pick a token.
+	Host   string
+	APIURL string
+	Owner  string
+	Name   string
+}
+
+// parseRepo accepts owner/repo or a repository URL. The API endpoint comes
+// from --api-url if set, else from the URL's host (github.com, a GHE.com
+// subdomain, a GitHub Enterprise Server or a GitLab instance), else from
+// $GITHUB_API_URL. An --api-url ending in /api/v4 selects GitLab.
+func parseRepo(arg string) (repoRef, error) {
+	var ref repoRef
+	if strings.Contains(arg, "://") {
+		u, parseErr := url.Parse(arg)
+		if parseErr != nil {
+			return repoRef{}, fmt.Errorf("invalid URL %q: %w", arg, parseErr)
+		}
+		if u.Host == "" {
+			return repoRef{}, fmt.Errorf("invalid URL %q: missing host", arg)
+		}
+		arg = strings.TrimPrefix(u.Path, "/")
+		if isGitLab(u.Host, arg) {
+			ref.GitLab = true
+			ref.Host, ref.APIURL = gitlabEndpoints(u.Scheme, u.Host)
+			arg = strings.TrimPrefix(arg, "api/v4/projects/")
+			arg, _, _ = strings.Cut(arg, "/-/")
+			arg = strings.TrimSuffix(strings.TrimSuffix(arg, "/"), ".git")
+		} else {
+			ref.Host, ref.APIURL = hostEndpoints(u.Scheme, u.Host)
+			arg = strings.TrimPrefix(arg, "api/v3/")
+			arg = strings.TrimPrefix(arg, "repos/")
+			arg = strings.TrimSuffix(arg, ".git")
+		}
+	} else {
+		var err error
+		if ref, err = defaultEndpoint(); err != nil {
+			return repoRef{}, err
+		}
+	}
+
+	ref, err := withAPIURL(ref)
+	if err != nil {
+		return repoRef{}, err
+	}
+
+	if ref.GitLab {
+		return parseProject(ref, arg)
+	}
+
+	parts := strings.SplitN(arg, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return repoRef{}, fmt.Errorf("invalid repo format %q, expected owner/repo or a GitHub URL", arg)
+	}
+	if !validRepoName.MatchString(parts[0]) || !validRepoName.MatchString(parts[1]) {
+		return repoRef{}, fmt.Errorf("invalid owner or repo name in %q", arg)
+	}
+	ref.Owner, ref.Name = parts[0], parts[1]
+	return ref, nil
+}
+
+// defaultEndpoint returns the instance used for arguments that don't name
+// a host: the one in $GITHUB_API_URL, else github.com.
+func defaultEndpoint() (repoRef, error) {
+	var ref repoRef
+	if env := os.Getenv("GITHUB_API_URL"); env != "" {
+		u, err := url.Parse(env)
+		if err != nil || u.Host == "" {
+			return repoRef{}, fmt.Errorf("invalid GITHUB_API_URL %q", env)
+		}
+		ref.Host, _ = hostEndpoints(u.Scheme, u.Host)
+		ref.APIURL = strings.TrimSuffix(env, "/")
+		return ref, nil
+	}
+	ref.Host, ref.APIURL = hostEndpoints("https", dotcomHost)
+	return ref, nil
+}
+
+// withAPIURL applies --api-url, if set, to ref.
+func withAPIURL(ref repoRef) (repoRef, error) {
+	if apiURL == "" {
+		return ref, nil
+	}
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return repoRef{}, fmt.Errorf("invalid --api-url %q", apiURL)
+	}
+	ref.APIURL = strings.TrimSuffix(apiURL, "/")
+	if ref.GitLab || strings.HasSuffix(ref.APIURL, "/api/v4") {
+		ref.GitLab = true
+		ref.Host = strings.ToLower(u.Host)
+	} else {
+		ref.Host, _ = hostEndpoints(u.Scheme, u.Host)
+	}
+	return ref, nil
+}
+
+// parseProject splits a GitLab project path into its namespace, which may
+// have subgroups, and the project name.
+func parseProject(ref repoRef, path string) (repoRef, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return repoRef{}, fmt.Errorf("invalid project path %q, expected group/project or a GitLab URL", path)
+	}
+	for _, p := range parts {
+		if !validRepoName.MatchString(p) {
+			return repoRef{}, fmt.Errorf("invalid group or project name in %q", path)
+		}
+	}
+	ref.Owner = strings.Join(parts[:len(parts)-1], "/")
+	ref.Name = parts[len(parts)-1]
+	return ref, nil
+}
+
+// isGitLab guesses from a repository URL whether it points at GitLab:
+// gitlab.com, a host named gitlab.*, the host in $GITLAB_HOST, or a path
+// using GitLab's /-/ separator, as in group/project/-/issues.
+func isGitLab(host, path string) bool {
+	host = strings.ToLower(host)
+	if host == gitlabHost || host == "www."+gitlabHost || strings.HasPrefix(host, "gitlab.") {
+		return true
+	}
+	if strings.Contains("/"+path+"/", "/-/") || strings.HasPrefix(path, "api/v4/") {
+		return true
+	}
+	return host == envGitLabHost()
+}
+
+func gitlabEndpoints(scheme, host string) (webHost, api string) {
+	webHost = strings.ToLower(host)
+	if webHost == "www."+gitlabHost {
+		webHost = gitlabHost
+	}
+	if scheme == "" || webHost == gitlabHost {
+		scheme = "https"
+	}
+	return webHost, scheme + "://" + webHost + "/api/v4"
+}
+
+// envGitLabHost returns the host named by $GITLAB_HOST, which like the glab
+// CLI may be a bare host name or a URL.
+func envGitLabHost() string {
+	env := os.Getenv("GITLAB_HOST")
+	if env == "" {
+		return ""
+	}
+	if u, err := url.Parse(env); err == nil && u.Host != "" {
+		return strings.ToLower(u.Host)
+	}
+	return strings.ToLower(env)
+}
+
+// hostEndpoints maps a web or API host to the instance's web host and REST
+// API base URL.
+func hostEndpoints(scheme, host string) (webHost, api string) {
+	host = strings.ToLower(host)
+	switch {
+	case host == dotcomHost || host == "www."+dotcomHost || host == "api."+dotcomHost:
+		return dotcomHost, "https://api.github.com"
+	case strings.HasSuffix(host, ".ghe.com"):
+		webHost = strings.TrimPrefix(host, "api.")
+		return webHost, "https://api." + webHost
+	}
+	if scheme == "" {
+		scheme = "https"
+	}
+	return host, scheme + "://" + host + "/api/v3"
+}
+
+// githubToken returns the token for a GitHub host. Enterprise Server hosts
+// use GH_ENTERPRISE_TOKEN or GITHUB_ENTERPRISE_TOKEN, like the gh CLI, so a
+// github.com token is never sent to another server. GITHUB_TOKEN is also
+// accepted for the host named by $GITHUB_API_URL, as set in Actions runners.
+func githubToken(host string) string {
+	if host == dotcomHost || strings.HasSuffix(host, ".ghe.com") {
+		return os.Getenv("GITHUB_TOKEN")
+	}
+	for _, name := range []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+		if token := os.Getenv(name); token != "" {
+			return token
+		}
+	}
+	if env := os.Getenv("GITHUB_API_URL"); env != "" {
+		if u, err := url.Parse(env); err == nil && strings.EqualFold(u.Host, host) {
+			return os.Getenv("GITHUB_TOKEN")
+		}
+	}
+	return ""
+}
+
+// gitlabToken returns the token for a GitLab host. GITLAB_TOKEN is only
+// sent to gitlab.com or to the self-managed host named by $GITLAB_HOST.
+func gitlabToken(host string) string {
+	if host == gitlabHost || host == envGitLabHost() {
+		return os.Getenv("GITLAB_TOKEN")
+	}
+	return ""
+}
+
+// newSource points the matching API client at the repository's instance
+// and returns the source to read its issues from.
+func newSource(ref repoRef) summarize.Source {
+	if ref.GitLab {
+		gitlab.SetBaseURL(ref.APIURL)
+		return gitlab.NewSource(ref.Owner+"/"+ref.Name, gitlabToken(ref.Host))
+	}
+	github.SetBaseURL(ref.APIURL)
+	return github.NewSource(ref.Owner, ref.Name, githubToken(ref.Host))
+}
@@ -2,67 +2,271 @@ package cmd
 
 import (
 	"fmt"
-	"net/url"
 	"os"
-	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/mrphil/gitissuesum/internal/github"
+	"github.com/mrphil/gitissuesum/internal/prompt"
 	"github.com/mrphil/gitissuesum/internal/summarize"
 	"github.com/spf13/cobra"
 )
 
-var validRepoName = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
-
 var (
 	maxIssues int
-	model     string
+	output    string
+	filter    summarize.Filter
+	since     string
+
+	includeComments bool
+	verbose         bool
+	templateName    string
+	maxInputTokens  int
+	countTokens     bool
+	dryRun          bool
+	dryRunFile      string
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "gitissuesum <owner/repo or GitHub URL>",
-	Short: "Summarize open GitHub issues using Claude",
-	Long:  "Fetches open issues from a GitHub repository and generates an AI-powered summary using Claude.",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		owner, name, err := parseRepo(args[0])
+	Use:   "gitissuesum [owner/repo, GitHub/GitLab URL or org:name]...",
+	Short: "Summarize open GitHub or GitLab issues using Claude",
+	Long: "Fetches issues (open ones by default) from a GitHub repository or GitLab project and generates an AI-powered summary " +
+		"using Claude or an OpenAI-compatible model. Given several repositories, or org:<name> for all of a GitHub " +
+		"organization's, it summarizes each and then writes a roll-up of the themes they share.",
+	Args: cobra.ArbitraryArgs,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyConfig(cmd); err != nil {
+			return err
+		}
+		for _, key := range cfg.Ignored {
+			logf("Warning: ignoring %s; only the user config file can set where requests and credentials go.\n", key)
+		}
+		github.SetVerbose(verbose)
+		enableCache()
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		args, err = repoArgs(args)
+		if err != nil {
+			return err
+		}
+		if output != summarize.OutputText && output != summarize.OutputJSON {
+			return fmt.Errorf("invalid --output %q, expected %q or %q", output, summarize.OutputText, summarize.OutputJSON)
+		}
+		if maxInputTokens < 0 {
+			return fmt.Errorf("invalid --max-input-tokens %d, expected 0 or more", maxInputTokens)
+		}
+		instructions, err := loadTemplate()
+		if err != nil {
+			return err
+		}
+		params, err := requestParams(cmd)
+		if err != nil {
+			return err
+		}
+		if dryRunFile != "" {
+			dryRun = true
+		}
+		var provider summarize.Provider
+		if dryRun {
+			recorder, dryRunErr := newDryRun(cmd)
+			if dryRunErr != nil {
+				return dryRunErr
+			}
+			// The requests are printed once the run has made them all.
+			defer func() {
+				if err == nil {
+					err = finishDryRun(recorder)
+				}
+			}()
+			provider = recorder
+		} else {
+			meter, meterErr := newMeter(cmd)
+			if meterErr != nil {
+				return meterErr
+			}
+			defer reportUsage(meter)
+			provider = meter
+		}
+
+		refs, err := resolveTargets(cmd.Context(), args)
 		if err != nil {
 			return err
 		}
+		if err := validateFilter(slices.ContainsFunc(refs, func(r repoRef) bool { return r.GitLab })); err != nil {
+			return err
+		}
+		if len(refs) > 1 && sinceLast {
+			return fmt.Errorf("--since-last works with a single repository, got %d", len(refs))
+		}
 
-		apiKey := os.Getenv("ANTHROPIC_API_KEY")
-		if apiKey == "" {
-			return fmt.Errorf("ANTHROPIC_API_KEY environment variable is required")
+		store, err := openHistory()
+		if err != nil && (sinceLast || !noHistory) {
+			return err
 		}
 
-		githubToken := os.Getenv("GITHUB_TOKEN")
+		opts := summarize.Options{
+			Provider:  provider,
+			Model:     model,
+			MaxIssues: maxIssues,
+			Output:    output,
+			Filter:    filter,
+
+			IncludeComments: includeComments,
+			Instructions:    instructions,
+			InputBudget:     maxInputTokens,
+			CountTokens:     countTokens,
+			Params:          params,
+		}
 
-		return summarize.Run(cmd.Context(), owner, name, apiKey, githubToken, model, maxIssues)
+		if len(refs) > 1 {
+			sources := make([]summarize.Source, len(refs))
+			byRepo := make(map[string]repoRef, len(refs))
+			for i, ref := range refs {
+				sources[i] = newSource(ref)
+				byRepo[sources[i].Repo()] = ref
+			}
+			snaps, err := summarize.RunAll(cmd.Context(), opts, sources, concurrency)
+			if !noHistory && !dryRun {
+				for _, snap := range snaps {
+					saveSnapshot(store, byRepo[snap.Repository], snap)
+				}
+			}
+			return err
+		}
+
+		ref := refs[0]
+		if sinceLast {
+			if opts.Previous, err = previousSnapshot(store, ref); err != nil {
+				return err
+			}
+		}
+		opts.Source = newSource(ref)
+		snap, err := summarize.Run(cmd.Context(), opts)
+		if err != nil {
+			return err
+		}
+		if !noHistory && !dryRun {
+			saveSnapshot(store, ref, snap)
+		}
+		return nil
 	},
 }
 
 func init() {
-	rootCmd.Flags().IntVar(&maxIssues, "max-issues", 200, "Maximum number of issues to fetch")
-	rootCmd.Flags().StringVar(&model, "model", "claude-sonnet-4-20250514", "Claude model to use")
+	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "GitHub API base URL, e.g. https://ghe.example.com/api/v3 (default from $GITHUB_API_URL or the repo URL)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Log each GitHub request and the remaining rate limit quota")
+	rootCmd.Flags().StringVarP(&output, "output", "o", summarize.OutputText, "Output format: text or json")
+	rootCmd.Flags().BoolVar(&includeComments, "include-comments", false, "Include a digest of each issue's comments in the analysis")
+	rootCmd.Flags().IntVar(&maxInputTokens, "max-input-tokens", 0, "Input tokens to fit each request in, trimming issue text to fit (default three quarters of the model's context window)")
+	rootCmd.Flags().BoolVar(&countTokens, "count-tokens", false, "Check the prompt size with the provider's token counting endpoint (Anthropic only) instead of estimating it")
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Fetch the issues and print the requests that would be sent to the model, without sending them")
+	rootCmd.Flags().StringVar(&dryRunFile, "dry-run-file", "", "With --dry-run (implied), also write the requests to this file as JSON Lines, one request body per line, for replay")
+	rootCmd.Flags().StringVarP(&templateName, "template", "t", "", "Prompt template for what the summary covers: a built-in ("+strings.Join(prompt.Builtins(), ", ")+") or a text/template file")
+	addIssueFlags(rootCmd)
 }
 
-func parseRepo(arg string) (owner, repo string, err error) {
-	if strings.Contains(arg, "://") {
-		u, parseErr := url.Parse(arg)
-		if parseErr != nil {
-			return "", "", fmt.Errorf("invalid URL %q: %w", arg, parseErr)
+// loadTemplate loads the --template instructions, or returns nil for the
+// standard ones.
+func loadTemplate() (summarize.Instructions, error) {
+	if templateName == "" {
+		return nil, nil
+	}
+	if output == summarize.OutputJSON {
+		return nil, fmt.Errorf("--template can't be used with --output json, which has a fixed schema")
+	}
+	if sinceLast {
+		return nil, fmt.Errorf("--template can't be used with --since-last")
+	}
+	tmpl, err := prompt.Load(templateName)
+	if err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// addIssueFlags registers the flags that choose which issues are fetched,
+// shared by every command that reads a repository's issues.
+func addIssueFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&maxIssues, "max-issues", 200, "Maximum number of issues to fetch")
+	cmd.Flags().StringVar(&filter.State, "state", "open", "Issue state: open, closed or all")
+	cmd.Flags().StringSliceVarP(&filter.Labels, "label", "l", nil, "Only include issues with this label (repeatable; all must match)")
+	cmd.Flags().StringVar(&filter.Assignee, "assignee", "", "Only include issues assigned to this user, \"none\" or \"*\"")
+	cmd.Flags().StringVar(&filter.Author, "author", "", "Only include issues opened by this user")
+	cmd.Flags().StringVar(&filter.Milestone, "milestone", "", "Only include issues in this milestone (number on GitHub, title on GitLab), \"none\" or \"*\"")
+	cmd.Flags().StringVar(&since, "since", "", "Only include issues updated since a date (2006-01-02 or RFC 3339) or duration ago (e.g. 36h, 7d, 2w)")
+	cmd.Flags().StringVar(&filter.Sort, "sort", "", "Sort issues by created, updated or comments (GitHub only)")
+	cmd.Flags().StringVar(&filter.Direction, "direction", "", "Sort direction: asc or desc")
+}
+
+func validateFilter(gitlab bool) error {
+	if err := oneOf("state", filter.State, "open", "closed", "all"); err != nil {
+		return err
+	}
+	if err := oneOf("sort", filter.Sort, "", "created", "updated", "comments"); err != nil {
+		return err
+	}
+	if err := oneOf("direction", filter.Direction, "", "asc", "desc"); err != nil {
+		return err
+	}
+	if gitlab && filter.Sort == "comments" {
+		return fmt.Errorf("--sort comments is not supported for GitLab projects")
+	}
+	if m := filter.Milestone; !gitlab && m != "" && m != "none" && m != "*" {
+		if _, err := strconv.Atoi(m); err != nil {
+			return fmt.Errorf("invalid --milestone %q, expected a milestone number, \"none\" or \"*\"", m)
+		}
+	}
+	if since != "" {
+		t, err := parseSince(since, time.Now())
+		if err != nil {
+			return err
 		}
-		arg = strings.TrimPrefix(u.Path, "/")
-		arg = strings.TrimSuffix(arg, ".git")
+		filter.Since = t
 	}
+	return nil
+}
 
-	parts := strings.SplitN(arg, "/", 3)
-	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
-		return "", "", fmt.Errorf("invalid repo format %q, expected owner/repo or a GitHub URL", arg)
+func oneOf(flag, value string, allowed ...string) error {
+	if slices.Contains(allowed, value) {
+		return nil
 	}
-	if !validRepoName.MatchString(parts[0]) || !validRepoName.MatchString(parts[1]) {
-		return "", "", fmt.Errorf("invalid owner or repo name in %q", arg)
+	return fmt.Errorf("invalid --%s %q, expected one of: %s", flag, value, strings.Join(slices.DeleteFunc(allowed, func(s string) bool { return s == "" }), ", "))
+}
+
+// parseSince accepts an absolute date or timestamp, or a duration that is
+// subtracted from now. Durations may use d and w units in addition to the
+// ones time.ParseDuration understands.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
 	}
-	return parts[0], parts[1], nil
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		if n, err := strconv.Atoi(s[:len(s)-1]); err == nil && n >= 0 {
+			return now.Add(-time.Duration(n) * unit), nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q, expected a date (2006-01-02), RFC 3339 timestamp or duration (e.g. 36h, 7d, 2w)", s)
+}
+
+// logf reports progress on stderr so stdout carries only the result.
+func logf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format, args...)
 }
 
 func Execute() error {
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/stats"
+	"github.com/mrphil/gitissuesum/internal/summarize"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsOutput  string
+	inactiveDays int
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [owner/repo or GitHub/GitLab URL]",
+	Short: "Report issue statistics without calling a model",
+	Long: "Fetches issues like the summary does and reports counts by label, age distribution, top authors, " +
+		"the most-commented issues, unlabeled issues and issues with no activity. No API key is needed.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, err := singleRepo(args)
+		if err != nil {
+			return err
+		}
+		if statsOutput != summarize.OutputText && statsOutput != summarize.OutputJSON {
+			return fmt.Errorf("invalid --output %q, expected %q or %q", statsOutput, summarize.OutputText, summarize.OutputJSON)
+		}
+		if inactiveDays < 0 {
+			return fmt.Errorf("invalid --inactive-days %d, expected 0 or more", inactiveDays)
+		}
+		if err := validateFilter(ref.GitLab); err != nil {
+			return err
+		}
+
+		source := newSource(ref)
+		logf("Fetching issues from %s...\n", source.Repo())
+		issues, err := source.FetchIssues(cmd.Context(), filter, maxIssues)
+		if err != nil {
+			return fmt.Errorf("failed to fetch issues: %w", err)
+		}
+
+		report := stats.Compute(source.Repo(), issues, time.Now(), time.Duration(inactiveDays)*24*time.Hour)
+		if statsOutput == summarize.OutputJSON {
+			return summarize.WriteJSON(report)
+		}
+		return stats.WriteText(os.Stdout, report)
+	},
+}
+
+func init() {
+	statsCmd.Flags().StringVarP(&statsOutput, "output", "o", summarize.OutputText, "Output format: text (tables) or json")
+	statsCmd.Flags().IntVar(&inactiveDays, "inactive-days", 30, "Report uncommented issues not updated for this many days as having no activity")
+	addIssueFlags(statsCmd)
+	rootCmd.AddCommand(statsCmd)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mrphil/gitissuesum/internal/github"
+)
+
+const orgPrefix = "org:"
+
+var concurrency int
+
+func init() {
+	rootCmd.Flags().IntVar(&concurrency, "concurrency", 4, "Number of repositories to work on at once when summarizing several")
+}
+
+// resolveTargets turns the command line arguments into repositories,
+// expanding each org:<name> into the organization's repositories and
+// dropping repeats.
+func resolveTargets(ctx context.Context, args []string) ([]repoRef, error) {
+	var refs []repoRef
+	seen := map[string]bool{}
+	add := func(ref repoRef) {
+		if key := historyKey(ref); !seen[key] {
+			seen[key] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, arg := range args {
+		org, ok := strings.CutPrefix(arg, orgPrefix)
+		if !ok {
+			ref, err := parseRepo(arg)
+			if err != nil {
+				return nil, err
+			}
+			add(ref)
+			continue
+		}
+
+		orgRefs, err := orgRepos(ctx, org)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range orgRefs {
+			add(ref)
+		}
+	}
+
+	if err := checkSameInstance(refs); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// orgRepos lists a GitHub organization's repositories, on the instance
+// owner/repo arguments would use.
+func orgRepos(ctx context.Context, org string) ([]repoRef, error) {
+	if !validRepoName.MatchString(org) {
+		return nil, fmt.Errorf("invalid organization name in %q", orgPrefix+org)
+	}
+	base, err := defaultEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	if base, err = withAPIURL(base); err != nil {
+		return nil, err
+	}
+	if base.GitLab {
+		return nil, fmt.Errorf("%s<name> is only supported for GitHub", orgPrefix)
+	}
+
+	github.SetBaseURL(base.APIURL)
+	logf("Listing repositories in %s...\n", org)
+	repos, err := github.FetchOrgRepos(ctx, org, githubToken(base.Host))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories in %s: %w", org, err)
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("organization %s has no repositories with issues", org)
+	}
+
+	refs := make([]repoRef, len(repos))
+	for i, r := range repos {
+		ref := base
+		ref.Owner, ref.Name = r.Owner.Login, r.Name
+		refs[i] = ref
+	}
+	return refs, nil
+}
+
+// checkSameInstance rejects repositories spread over several GitHub or
+// GitLab instances, since each API client talks to one instance per run.
+func checkSameInstance(refs []repoRef) error {
+	apis := map[bool]string{}
+	for _, ref := range refs {
+		if api, ok := apis[ref.GitLab]; ok && api != ref.APIURL {
+			return fmt.Errorf("repositories must be on the same instance, got %s and %s", api, ref.APIURL)
+		}
+		apis[ref.GitLab] = ref.APIURL
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+	"github.com/spf13/cobra"
+)
+
+var triageOutput string
+
+var triageCmd = &cobra.Command{
+	Use:   "triage [owner/repo or GitHub/GitLab URL]",
+	Short: "Suggest triage actions for a repository's issues",
+	Long: "Has the model go through the issues with structured tools that suggest labels, mark duplicates, " +
+		"suggest closing issues and set priorities, and prints the suggestions as an action plan to review. " +
+		"Nothing is changed in the repository. Only the anthropic provider is supported.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ref, err := singleRepo(args)
+		if err != nil {
+			return err
+		}
+		if providerName != providerAnthropic {
+			return fmt.Errorf("triage needs tool use, which is only supported with --provider %s", providerAnthropic)
+		}
+		if triageOutput != summarize.OutputText && triageOutput != summarize.OutputJSON {
+			return fmt.Errorf("invalid --output %q, expected %q or %q", triageOutput, summarize.OutputText, summarize.OutputJSON)
+		}
+		if err := validateFilter(ref.GitLab); err != nil {
+			return err
+		}
+		params, err := requestParams(cmd)
+		if err != nil {
+			return err
+		}
+		provider, err := newMeter(cmd)
+		if err != nil {
+			return err
+		}
+		defer reportUsage(provider)
+
+		plan, err := summarize.Triage(cmd.Context(), summarize.Options{
+			Source:          newSource(ref),
+			Provider:        provider,
+			Model:           model,
+			MaxIssues:       maxIssues,
+			Filter:          filter,
+			IncludeComments: includeComments,
+			Params:          params,
+		})
+		if err != nil {
+			return err
+		}
+		if triageOutput == summarize.OutputJSON {
+			return summarize.WriteJSON(plan)
+		}
+		return plan.WriteText(os.Stdout)
+	},
+}
+
+func init() {
+	triageCmd.Flags().StringVarP(&triageOutput, "output", "o", summarize.OutputText, "Output format: text (a Markdown checklist) or json")
+	triageCmd.Flags().BoolVar(&includeComments, "include-comments", false, "Include a digest of each issue's comments in the analysis")
+	addIssueFlags(triageCmd)
+	rootCmd.AddCommand(triageCmd)
+}
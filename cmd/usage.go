@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+	"github.com/spf13/cobra"
+)
+
+var maxCost float64
+
+func init() {
+	rootCmd.PersistentFlags().Float64Var(&maxCost, "max-cost", 0, "Stop before any request that could take the run's estimated cost over this many US dollars (0 for no limit)")
+}
+
+// newMeter returns the selected provider wrapped to add up token usage and
+// enforce --max-cost, with the model's price taken from the config or the
+// built-in table.
+func newMeter(cmd *cobra.Command) (*summarize.Meter, error) {
+	if maxCost < 0 {
+		return nil, fmt.Errorf("invalid --max-cost %v, expected 0 or more", maxCost)
+	}
+	provider, err := newProvider(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]summarize.Price, len(cfg.Prices))
+	for prefix, p := range cfg.Prices {
+		overrides[prefix] = summarize.Price{Input: p.Input, Output: p.Output, CacheWrite: p.CacheWrite, CacheRead: p.CacheRead}
+	}
+	price, priced := summarize.PriceFor(model, overrides)
+	if maxCost > 0 && !priced {
+		return nil, fmt.Errorf("--max-cost needs the price of %s; add it under prices in the config file", model)
+	}
+	return &summarize.Meter{Provider: provider, Price: price, Priced: priced, MaxCost: maxCost}, nil
+}
+
+// reportUsage logs the run's token usage and estimated cost, if it made any
+// requests.
+func reportUsage(m *summarize.Meter) {
+	if r := m.Report(); r.Requests > 0 {
+		logf("Usage: %s.\n", r)
+	}
+}
+
+// newDryRun returns a provider that records requests instead of sending
+// them. Token counting still goes to the provider if --count-tokens asks
+// for it, which needs its credentials; otherwise none are needed.
+func newDryRun(cmd *cobra.Command) (*summarize.DryRun, error) {
+	recorder := &summarize.DryRun{}
+	if countTokens {
+		provider, err := newProvider(cmd)
+		if err != nil {
+			return nil, err
+		}
+		recorder.Counter, _ = provider.(summarize.TokenCounter)
+	}
+	return recorder, nil
+}
+
+// finishDryRun prints the recorded requests and writes them to
+// --dry-run-file if it was given.
+func finishDryRun(recorder *summarize.DryRun) error {
+	if err := recorder.WriteText(os.Stdout); err != nil {
+		return err
+	}
+	if dryRunFile == "" {
+		return nil
+	}
+	f, err := os.Create(dryRunFile)
+	if err != nil {
+		return fmt.Errorf("failed to write requests: %w", err)
+	}
+	if err := recorder.WriteRequests(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write requests: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write requests: %w", err)
+	}
+	logf("Wrote %d requests to %s.\n", len(recorder.Requests()), dryRunFile)
+	return nil
+}
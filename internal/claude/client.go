@@ -7,70 +7,143 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
+const defaultMaxTokens = 4096
+
 var (
 	apiURL     = "https://api.anthropic.com/v1/messages"
 	httpClient = &http.Client{Timeout: 120 * time.Second}
+	// streamClient has no overall timeout because a streamed response stays
+	// open for as long as the model is generating; ctx bounds it instead.
+	streamClient = &http.Client{}
 )
 
-func SendMessage(ctx context.Context, apiKey, model, prompt string) (string, error) {
-	reqBody := Request{
-		Model:     model,
-		MaxTokens: 4096,
-		Messages: []Message{
-			{Role: "user", Content: prompt},
-		},
-	}
+// Client sends requests to the Messages API.
+type Client struct {
+	APIKey string
+	// BaseURL replaces https://api.anthropic.com, e.g. to go through a proxy.
+	BaseURL string
+}
 
-	body, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+func NewClient(apiKey string) *Client {
+	return &Client{APIKey: apiKey}
+}
+
+// NewRequest returns a request for a single user prompt.
+func NewRequest(model, prompt string) Request {
+	return NewChatRequest(model, []Message{{Role: "user", Content: prompt}})
+}
+
+// NewChatRequest returns a request continuing a conversation, whose
+// messages alternate between user and assistant and end with the user's.
+func NewChatRequest(model string, messages []Message) Request {
+	return Request{
+		Model:     model,
+		MaxTokens: defaultMaxTokens,
+		Messages:  messages,
 	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(body))
+func (c *Client) Send(ctx context.Context, reqBody Request) (*Response, error) {
+	req, body, err := c.newHTTPRequest(ctx, c.messagesURL(), reqBody)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-API-Key", apiKey)
-	req.Header.Set("Anthropic-Version", "2023-06-01")
 
-	resp, err := doWithRetry(req, body)
+	resp, err := doWithRetry(httpClient, req, body)
 	if err != nil {
-		return "", fmt.Errorf("Anthropic API request failed: %w", err)
+		return nil, fmt.Errorf("Anthropic API request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		var errResp Response
-		msg := fmt.Sprintf("Anthropic API returned status %d", resp.StatusCode)
-		if json.NewDecoder(resp.Body).Decode(&errResp) == nil && errResp.Error != nil {
-			msg += ": " + errResp.Error.Type
-		}
-		return "", fmt.Errorf("%s", msg)
+	if err := checkStatus(resp); err != nil {
+		return nil, err
 	}
 
 	var result Response
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("failed to decode Anthropic response: %w", err)
+		return nil, fmt.Errorf("failed to decode Anthropic response: %w", err)
 	}
 
 	if len(result.Content) == 0 {
-		return "", fmt.Errorf("empty response from Claude")
+		return nil, fmt.Errorf("empty response from Claude")
 	}
+	return &result, nil
+}
 
-	var text string
-	for _, block := range result.Content {
+// Truncated reports whether the reply was cut off at the request's
+// MaxTokens.
+func (r *Response) Truncated() bool {
+	return r.StopReason == StopMaxTokens
+}
+
+// SupportsPrefill reports that the Messages API carries on from a
+// conversation ending in a partial assistant message.
+func (c *Client) SupportsPrefill() bool {
+	return true
+}
+
+// ToolUses returns the response's tool calls, in order.
+func (r *Response) ToolUses() []ContentBlock {
+	var uses []ContentBlock
+	for _, block := range r.Content {
+		if block.Type == "tool_use" {
+			uses = append(uses, block)
+		}
+	}
+	return uses
+}
+
+// Text concatenates the response's text blocks.
+func (r *Response) Text() string {
+	var text strings.Builder
+	for _, block := range r.Content {
 		if block.Type == "text" {
-			text += block.Text
+			text.WriteString(block.Text)
 		}
 	}
-	return text, nil
+	return text.String()
+}
+
+func (c *Client) messagesURL() string {
+	if c.BaseURL != "" {
+		return strings.TrimSuffix(c.BaseURL, "/") + "/v1/messages"
+	}
+	return apiURL
+}
+
+func (c *Client) newHTTPRequest(ctx context.Context, url string, reqBody any) (*http.Request, []byte, error) {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", c.APIKey)
+	req.Header.Set("Anthropic-Version", "2023-06-01")
+	return req, body, nil
+}
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	var errResp Response
+	msg := fmt.Sprintf("Anthropic API returned status %d", resp.StatusCode)
+	if json.NewDecoder(resp.Body).Decode(&errResp) == nil && errResp.Error != nil {
+		msg += ": " + errResp.Error.Type
+	}
+	return fmt.Errorf("%s", msg)
 }
 
-func doWithRetry(req *http.Request, body []byte) (*http.Response, error) {
+func doWithRetry(client *http.Client, req *http.Request, body []byte) (*http.Response, error) {
 	backoff := []time.Duration{0, 1 * time.Second, 2 * time.Second}
 	var resp *http.Response
 	var err error
@@ -79,7 +152,7 @@ func doWithRetry(req *http.Request, body []byte) (*http.Response, error) {
 			time.Sleep(wait)
 			req.Body = io.NopCloser(bytes.NewReader(body))
 		}
-		resp, err = httpClient.Do(req)
+		resp, err = client.Do(req)
 		if err != nil {
 			continue
 		}
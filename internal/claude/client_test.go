@@ -1,6 +1,7 @@
 package claude
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"io"
@@ -9,7 +10,7 @@ import (
 	"testing"
 )
 
-func TestSendMessage_Success(t *testing.T) {
+func TestSend_Success(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(Response{
 			Content: []ContentBlock{{Type: "text", Text: "hello world"}},
@@ -21,16 +22,99 @@ func TestSendMessage_Success(t *testing.T) {
 	apiURL = srv.URL
 	defer func() { apiURL = old }()
 
-	got, err := SendMessage(context.Background(), "key", "model", "prompt")
+	resp, err := NewClient("key").Send(context.Background(), NewRequest("model", "prompt"))
 	if err != nil {
-		t.Fatalf("SendMessage() error: %v", err)
+		t.Fatalf("Send() error: %v", err)
 	}
-	if got != "hello world" {
+	if got := resp.Text(); got != "hello world" {
 		t.Errorf("got %q, want 'hello world'", got)
 	}
 }
 
-func TestSendMessage_MultiBlock(t *testing.T) {
+func TestSend_Usage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":10,"output_tokens":5,"cache_creation_input_tokens":200}}`))
+	}))
+	defer srv.Close()
+
+	old := apiURL
+	apiURL = srv.URL
+	defer func() { apiURL = old }()
+
+	resp, err := NewClient("key").Send(context.Background(), NewRequest("model", "prompt"))
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	want := Usage{InputTokens: 10, OutputTokens: 5, CacheCreationInputTokens: 200}
+	if resp.Usage != want {
+		t.Errorf("usage = %+v, want %+v", resp.Usage, want)
+	}
+}
+
+func TestSend_MaxTokens(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[{"type":"text","text":"the start of"}],"stop_reason":"max_tokens"}`))
+	}))
+	defer srv.Close()
+
+	old := apiURL
+	apiURL = srv.URL
+	defer func() { apiURL = old }()
+
+	resp, err := NewClient("key").Send(context.Background(), NewRequest("model", "prompt"))
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if !resp.Truncated() || resp.Text() != "the start of" {
+		t.Errorf("Send() = %q (stop reason %q), want the partial reply marked as cut off", resp.Text(), resp.StopReason)
+	}
+}
+
+func TestSend_ToolUse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req Request
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Name != "set_priority" || string(req.Tools[0].InputSchema) != `{"type":"object"}` {
+			t.Errorf("tools = %+v", req.Tools)
+		}
+		result := req.Messages[2].Blocks[0]
+		if result.Type != "tool_result" || result.ToolUseID != "tu_1" || !result.IsError || result.Content != "issue is required" {
+			t.Errorf("tool result block = %+v", result)
+		}
+		if bytes.Contains(body, []byte(`"text":""`)) {
+			t.Errorf("tool blocks should not carry an empty text field: %s", body)
+		}
+		w.Write([]byte(`{"content":[{"type":"text","text":"Setting it."},{"type":"tool_use","id":"tu_2","name":"set_priority","input":{"issue":3}}],"stop_reason":"tool_use"}`))
+	}))
+	defer srv.Close()
+
+	old := apiURL
+	apiURL = srv.URL
+	defer func() { apiURL = old }()
+
+	req := NewChatRequest("model", []Message{
+		{Role: "user", Content: "prompt"},
+		{Role: "assistant", Blocks: []ContentBlock{{Type: "tool_use", ID: "tu_1", Name: "set_priority", Input: json.RawMessage(`{}`)}}},
+		{Role: "user", Blocks: []ContentBlock{ToolResult("tu_1", "issue is required", true)}},
+	})
+	req.Tools = []Tool{{Name: "set_priority", Description: "Set an issue's priority", InputSchema: json.RawMessage(`{"type":"object"}`)}}
+	resp, err := NewClient("key").Send(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	uses := resp.ToolUses()
+	if resp.StopReason != StopToolUse || len(uses) != 1 || uses[0].ID != "tu_2" || string(uses[0].Input) != `{"issue":3}` {
+		t.Errorf("tool uses = %+v (stop reason %q)", uses, resp.StopReason)
+	}
+	if resp.Text() != "Setting it." {
+		t.Errorf("text = %q", resp.Text())
+	}
+}
+
+func TestSend_MultiBlock(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(Response{
 			Content: []ContentBlock{
@@ -45,16 +129,16 @@ func TestSendMessage_MultiBlock(t *testing.T) {
 	apiURL = srv.URL
 	defer func() { apiURL = old }()
 
-	got, err := SendMessage(context.Background(), "key", "model", "prompt")
+	resp, err := NewClient("key").Send(context.Background(), NewRequest("model", "prompt"))
 	if err != nil {
-		t.Fatalf("SendMessage() error: %v", err)
+		t.Fatalf("Send() error: %v", err)
 	}
-	if got != "part1part2" {
+	if got := resp.Text(); got != "part1part2" {
 		t.Errorf("got %q, want 'part1part2'", got)
 	}
 }
 
-func TestSendMessage_APIError(t *testing.T) {
+func TestSend_APIError(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(Response{
@@ -67,7 +151,7 @@ func TestSendMessage_APIError(t *testing.T) {
 	apiURL = srv.URL
 	defer func() { apiURL = old }()
 
-	_, err := SendMessage(context.Background(), "key", "model", "prompt")
+	_, err := NewClient("key").Send(context.Background(), NewRequest("model", "prompt"))
 	if err == nil {
 		t.Fatal("expected error for 400 status")
 	}
@@ -76,7 +160,7 @@ func TestSendMessage_APIError(t *testing.T) {
 	}
 }
 
-func TestSendMessage_Non200NoError(t *testing.T) {
+func TestSend_Non200NoError(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(Response{})
@@ -87,13 +171,13 @@ func TestSendMessage_Non200NoError(t *testing.T) {
 	apiURL = srv.URL
 	defer func() { apiURL = old }()
 
-	_, err := SendMessage(context.Background(), "key", "model", "prompt")
+	_, err := NewClient("key").Send(context.Background(), NewRequest("model", "prompt"))
 	if err == nil {
 		t.Fatal("expected error for 500 status")
 	}
 }
 
-func TestSendMessage_EmptyContent(t *testing.T) {
+func TestSend_EmptyContent(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(Response{Content: []ContentBlock{}})
 	}))
@@ -103,13 +187,13 @@ func TestSendMessage_EmptyContent(t *testing.T) {
 	apiURL = srv.URL
 	defer func() { apiURL = old }()
 
-	_, err := SendMessage(context.Background(), "key", "model", "prompt")
+	_, err := NewClient("key").Send(context.Background(), NewRequest("model", "prompt"))
 	if err == nil {
 		t.Fatal("expected error for empty content")
 	}
 }
 
-func TestSendMessage_RequestValidation(t *testing.T) {
+func TestSend_RequestValidation(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			t.Errorf("method = %s, want POST", r.Method)
@@ -146,8 +230,35 @@ func TestSendMessage_RequestValidation(t *testing.T) {
 	apiURL = srv.URL
 	defer func() { apiURL = old }()
 
-	_, err := SendMessage(context.Background(), "test-key", "test-model", "test-prompt")
+	_, err := NewClient("test-key").Send(context.Background(), NewRequest("test-model", "test-prompt"))
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+}
+
+func TestCountTokens(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages/count_tokens" {
+			t.Errorf("path = %q, want /v1/messages/count_tokens", r.URL.Path)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := body["max_tokens"]; ok {
+			t.Error("count_tokens request should not carry max_tokens")
+		}
+		if body["model"] != "m" {
+			t.Errorf("model = %v, want m", body["model"])
+		}
+		json.NewEncoder(w).Encode(map[string]int{"input_tokens": 1234})
+	}))
+	defer srv.Close()
+
+	client := &Client{APIKey: "key", BaseURL: srv.URL}
+	got, err := client.CountTokens(context.Background(), NewRequest("m", "hello"))
 	if err != nil {
-		t.Fatalf("SendMessage() error: %v", err)
+		t.Fatalf("CountTokens() error: %v", err)
+	}
+	if got != 1234 {
+		t.Errorf("CountTokens() = %d, want 1234", got)
 	}
 }
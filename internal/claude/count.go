@@ -0,0 +1,50 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// countRequest is the body of a count_tokens request, which takes the
+// request's input without the generation settings.
+type countRequest struct {
+	Model    string    `json:"model"`
+	System   string    `json:"system,omitempty"`
+	Messages []Message `json:"messages"`
+	Tools    []Tool    `json:"tools,omitempty"`
+}
+
+type countResponse struct {
+	InputTokens int `json:"input_tokens"`
+}
+
+// CountTokens returns how many input tokens reqBody would use, without
+// running the model.
+func (c *Client) CountTokens(ctx context.Context, reqBody Request) (int, error) {
+	req, body, err := c.newHTTPRequest(ctx, c.messagesURL()+"/count_tokens", countRequest{
+		Model:    reqBody.Model,
+		System:   reqBody.System,
+		Messages: reqBody.Messages,
+		Tools:    reqBody.Tools,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := doWithRetry(httpClient, req, body)
+	if err != nil {
+		return 0, fmt.Errorf("Anthropic API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return 0, err
+	}
+
+	var result countResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode token count: %w", err)
+	}
+	return result.InputTokens, nil
+}
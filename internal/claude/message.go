@@ -0,0 +1,84 @@
+package claude
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// messageJSON is the wire form of Message, whose content is either a string
+// or a list of content blocks.
+type messageJSON struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// CachedMessage returns a message of prefix followed by rest, with the
+// prompt cached up to the end of prefix. Requests that repeat the prompt
+// up to there, whatever follows, then read it from the cache.
+func CachedMessage(role, prefix, rest string) Message {
+	blocks := []ContentBlock{{Type: "text", Text: prefix, CacheControl: Ephemeral}}
+	if rest != "" {
+		blocks = append(blocks, ContentBlock{Type: "text", Text: rest})
+	}
+	return Message{Role: role, Blocks: blocks}
+}
+
+// Text returns the message's text, joining its blocks if it has them.
+func (m Message) Text() string {
+	if m.Blocks == nil {
+		return m.Content
+	}
+	var text strings.Builder
+	for _, b := range m.Blocks {
+		text.WriteString(b.Text)
+	}
+	return text.String()
+}
+
+// Cached reports whether any of the message's blocks is a cache
+// breakpoint.
+func (m Message) Cached() bool {
+	for _, b := range m.Blocks {
+		if b.CacheControl != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (m Message) MarshalJSON() ([]byte, error) {
+	var content any = m.Content
+	if m.Blocks != nil {
+		content = m.Blocks
+	}
+	raw, err := marshalUnescaped(content)
+	if err != nil {
+		return nil, err
+	}
+	return marshalUnescaped(messageJSON{Role: m.Role, Content: raw})
+}
+
+// marshalUnescaped is json.Marshal without HTML escaping, which the caller's
+// encoder applies if it wants it; prompts are full of <, > and &.
+func marshalUnescaped(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var msg messageJSON
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	*m = Message{Role: msg.Role}
+	if bytes.HasPrefix(msg.Content, []byte("[")) {
+		return json.Unmarshal(msg.Content, &m.Blocks)
+	}
+	return json.Unmarshal(msg.Content, &m.Content)
+}
@@ -0,0 +1,37 @@
+package claude
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMessage_JSON(t *testing.T) {
+	plain, err := json.Marshal(Message{Role: "user", Content: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plain) != `{"role":"user","content":"hi"}` {
+		t.Errorf("plain message = %s", plain)
+	}
+
+	cached := CachedMessage("user", "issues", "question")
+	data, err := json.Marshal(cached)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"role":"user","content":[{"type":"text","text":"issues","cache_control":{"type":"ephemeral"}},{"type":"text","text":"question"}]}`
+	if string(data) != want {
+		t.Errorf("cached message =\n%s\nwant\n%s", data, want)
+	}
+
+	var back Message
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Text() != "issuesquestion" || !back.Cached() {
+		t.Errorf("round trip = %+v", back)
+	}
+	if err := json.Unmarshal(plain, &back); err != nil || back.Content != "hi" || back.Blocks != nil {
+		t.Errorf("plain round trip = %+v, %v", back, err)
+	}
+}
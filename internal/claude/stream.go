@@ -0,0 +1,122 @@
+package claude
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Stream is Send with streaming enabled. If the stream fails part way, the
+// text received so far is returned along with the error. Only text is
+// assembled, so requests with tools should use Send.
+func (c *Client) Stream(ctx context.Context, reqBody Request, onText func(string)) (*Response, error) {
+	reqBody.Stream = true
+	req, body, err := c.newHTTPRequest(ctx, c.messagesURL(), reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := doWithRetry(streamClient, req, body)
+	if err != nil {
+		return nil, fmt.Errorf("Anthropic API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+
+	return readStream(resp.Body, onText)
+}
+
+// readStream assembles the response from the stream's events, taking the
+// input token counts from message_start and the output count and stop
+// reason from the last message_delta.
+func readStream(r io.Reader, onText func(string)) (*Response, error) {
+	var text strings.Builder
+	var usage Usage
+	var stopReason string
+	started := false
+
+	err := scanEvents(r, func(data string) (bool, error) {
+		var event StreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return false, fmt.Errorf("failed to decode stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "message_start":
+			started = true
+			if event.Message != nil {
+				usage = event.Message.Usage
+			}
+		case "message_delta":
+			if event.Usage != nil {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+			if event.Delta != nil && event.Delta.StopReason != "" {
+				stopReason = event.Delta.StopReason
+			}
+		case "content_block_delta":
+			if !started {
+				return false, fmt.Errorf("unexpected %s before message_start", event.Type)
+			}
+			if event.Delta != nil && event.Delta.Type == "text_delta" {
+				text.WriteString(event.Delta.Text)
+				if onText != nil {
+					onText(event.Delta.Text)
+				}
+			}
+		case "message_stop":
+			return true, nil
+		case "error":
+			if event.Error != nil {
+				return false, fmt.Errorf("Anthropic API stream error: %s: %s", event.Error.Type, event.Error.Message)
+			}
+			return false, fmt.Errorf("Anthropic API stream error")
+		}
+		return false, nil
+	})
+	return &Response{Content: []ContentBlock{{Type: "text", Text: text.String()}}, StopReason: stopReason, Usage: usage}, err
+}
+
+// scanEvents splits an SSE body into events and passes each event's data to
+// handle until handle reports done. Reaching the end of the body before that
+// is an error, since a complete message always ends with message_stop.
+func scanEvents(r io.Reader, handle func(data string) (done bool, err error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) == 0 {
+				continue
+			}
+			done, err := handle(strings.Join(data, "\n"))
+			if err != nil || done {
+				return err
+			}
+			data = data[:0]
+			continue
+		}
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			data = append(data, strings.TrimPrefix(value, " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+	if len(data) > 0 {
+		done, err := handle(strings.Join(data, "\n"))
+		if err != nil || done {
+			return err
+		}
+	}
+	return fmt.Errorf("stream ended before message_stop")
+}
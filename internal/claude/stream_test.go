@@ -0,0 +1,232 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func sseServer(t *testing.T, events ...string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, e := range events {
+			fmt.Fprint(w, e)
+		}
+	}))
+}
+
+func sseEvent(name, data string) string {
+	return fmt.Sprintf("event: %s\ndata: %s\n\n", name, data)
+}
+
+func textDelta(text string) string {
+	return sseEvent("content_block_delta", fmt.Sprintf(`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":%q}}`, text))
+}
+
+func TestStream_Success(t *testing.T) {
+	srv := sseServer(t,
+		sseEvent("message_start", `{"type":"message_start","message":{"id":"msg_1"}}`),
+		sseEvent("content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`),
+		sseEvent("ping", `{"type":"ping"}`),
+		textDelta("hello"),
+		textDelta(" world"),
+		sseEvent("content_block_stop", `{"type":"content_block_stop","index":0}`),
+		sseEvent("message_delta", `{"type":"message_delta","delta":{"stop_reason":"end_turn"}}`),
+		sseEvent("message_stop", `{"type":"message_stop"}`),
+	)
+	defer srv.Close()
+
+	old := apiURL
+	apiURL = srv.URL
+	defer func() { apiURL = old }()
+
+	var deltas []string
+	resp, err := NewClient("key").Stream(context.Background(), NewRequest("model", "prompt"), func(s string) {
+		deltas = append(deltas, s)
+	})
+	if err != nil {
+		t.Fatalf("Stream() error: %v", err)
+	}
+	if got := resp.Text(); got != "hello world" {
+		t.Errorf("got %q, want 'hello world'", got)
+	}
+	if len(deltas) != 2 || deltas[0] != "hello" || deltas[1] != " world" {
+		t.Errorf("deltas = %q, want [hello, ' world']", deltas)
+	}
+}
+
+func TestStream_ErrorEvent(t *testing.T) {
+	srv := sseServer(t,
+		sseEvent("message_start", `{"type":"message_start","message":{"id":"msg_1"}}`),
+		textDelta("partial"),
+		sseEvent("error", `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`),
+	)
+	defer srv.Close()
+
+	old := apiURL
+	apiURL = srv.URL
+	defer func() { apiURL = old }()
+
+	resp, err := NewClient("key").Stream(context.Background(), NewRequest("model", "prompt"), nil)
+	if err == nil {
+		t.Fatal("expected error for mid-stream error event")
+	}
+	if !strings.Contains(err.Error(), "overloaded_error") {
+		t.Errorf("error = %q, want it to mention overloaded_error", err)
+	}
+	if got := resp.Text(); got != "partial" {
+		t.Errorf("partial text = %q, want 'partial'", got)
+	}
+}
+
+func TestStream_MissingMessageStop(t *testing.T) {
+	srv := sseServer(t,
+		sseEvent("message_start", `{"type":"message_start","message":{"id":"msg_1"}}`),
+		textDelta("cut off"),
+	)
+	defer srv.Close()
+
+	old := apiURL
+	apiURL = srv.URL
+	defer func() { apiURL = old }()
+
+	_, err := NewClient("key").Stream(context.Background(), NewRequest("model", "prompt"), nil)
+	if err == nil {
+		t.Fatal("expected error when stream ends before message_stop")
+	}
+}
+
+func TestStream_DeltaBeforeStart(t *testing.T) {
+	srv := sseServer(t,
+		textDelta("too early"),
+		sseEvent("message_stop", `{"type":"message_stop"}`),
+	)
+	defer srv.Close()
+
+	old := apiURL
+	apiURL = srv.URL
+	defer func() { apiURL = old }()
+
+	_, err := NewClient("key").Stream(context.Background(), NewRequest("model", "prompt"), nil)
+	if err == nil {
+		t.Fatal("expected error for delta before message_start")
+	}
+}
+
+func TestStream_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(Response{
+			Error: &APIError{Type: "authentication_error", Message: "bad key"},
+		})
+	}))
+	defer srv.Close()
+
+	old := apiURL
+	apiURL = srv.URL
+	defer func() { apiURL = old }()
+
+	_, err := NewClient("key").Stream(context.Background(), NewRequest("model", "prompt"), nil)
+	if err == nil {
+		t.Fatal("expected error for 401 status")
+	}
+	if got := err.Error(); got != "Anthropic API returned status 401: authentication_error" {
+		t.Errorf("error = %q", got)
+	}
+}
+
+func TestStream_RequestValidation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept"); got != "text/event-stream" {
+			t.Errorf("Accept = %q, want 'text/event-stream'", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req Request
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		if !req.Stream {
+			t.Error("stream = false, want true")
+		}
+		fmt.Fprint(w, sseEvent("message_start", `{"type":"message_start","message":{}}`))
+		fmt.Fprint(w, sseEvent("message_stop", `{"type":"message_stop"}`))
+	}))
+	defer srv.Close()
+
+	old := apiURL
+	apiURL = srv.URL
+	defer func() { apiURL = old }()
+
+	if _, err := NewClient("key").Stream(context.Background(), NewRequest("model", "prompt"), nil); err != nil {
+		t.Fatalf("Stream() error: %v", err)
+	}
+}
+
+func TestScanEvents_MultiLineData(t *testing.T) {
+	body := "event: x\ndata: line1\ndata: line2\n\n"
+	var got []string
+	err := scanEvents(strings.NewReader(body), func(data string) (bool, error) {
+		got = append(got, data)
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("scanEvents() error: %v", err)
+	}
+	if len(got) != 1 || got[0] != "line1\nline2" {
+		t.Errorf("got %q, want one event 'line1\\nline2'", got)
+	}
+}
+
+func TestStream_Usage(t *testing.T) {
+	srv := sseServer(t,
+		sseEvent("message_start", `{"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":120,"cache_read_input_tokens":30,"output_tokens":1}}}`),
+		textDelta("hi"),
+		sseEvent("message_delta", `{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":42}}`),
+		sseEvent("message_stop", `{"type":"message_stop"}`),
+	)
+	defer srv.Close()
+
+	old := apiURL
+	apiURL = srv.URL
+	defer func() { apiURL = old }()
+
+	resp, err := NewClient("key").Stream(context.Background(), NewRequest("model", "prompt"), nil)
+	if err != nil {
+		t.Fatalf("Stream() error: %v", err)
+	}
+	want := Usage{InputTokens: 120, OutputTokens: 42, CacheReadInputTokens: 30}
+	if resp.Usage != want {
+		t.Errorf("usage = %+v, want %+v", resp.Usage, want)
+	}
+	if resp.StopReason != "end_turn" || resp.Truncated() {
+		t.Errorf("stop reason = %q, want end_turn", resp.StopReason)
+	}
+}
+
+func TestStream_MaxTokens(t *testing.T) {
+	srv := sseServer(t,
+		sseEvent("message_start", `{"type":"message_start","message":{"id":"msg_1"}}`),
+		textDelta("the start of"),
+		sseEvent("message_delta", `{"type":"message_delta","delta":{"stop_reason":"max_tokens"},"usage":{"output_tokens":4096}}`),
+		sseEvent("message_stop", `{"type":"message_stop"}`),
+	)
+	defer srv.Close()
+
+	old := apiURL
+	apiURL = srv.URL
+	defer func() { apiURL = old }()
+
+	resp, err := NewClient("key").Stream(context.Background(), NewRequest("model", "prompt"), nil)
+	if err != nil {
+		t.Fatalf("Stream() error: %v", err)
+	}
+	if !resp.Truncated() || resp.Text() != "the start of" {
+		t.Errorf("Stream() = %q (stop reason %q), want the partial reply marked as cut off", resp.Text(), resp.StopReason)
+	}
+}
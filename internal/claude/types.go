@@ -1,27 +1,141 @@
 package claude
 
+import "encoding/json"
+
 type Request struct {
-	Model     string    `json:"model"`
-	MaxTokens int       `json:"max_tokens"`
-	Messages  []Message `json:"messages"`
+	Model     string `json:"model"`
+	MaxTokens int    `json:"max_tokens"`
+	// System sets the model's role and ground rules, apart from the
+	// conversation.
+	System        string    `json:"system,omitempty"`
+	Messages      []Message `json:"messages"`
+	Temperature   *float64  `json:"temperature,omitempty"`
+	TopP          *float64  `json:"top_p,omitempty"`
+	StopSequences []string  `json:"stop_sequences,omitempty"`
+	Metadata      *Metadata `json:"metadata,omitempty"`
+	// Tools are the tools the model may call; its calls come back as
+	// tool_use blocks, and their results go in tool_result blocks of the
+	// next user message.
+	Tools  []Tool `json:"tools,omitempty"`
+	Stream bool   `json:"stream,omitempty"`
+}
+
+// Tool describes a tool to the model.
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// InputSchema is the JSON Schema of the tool's input object.
+	InputSchema json.RawMessage `json:"input_schema"`
 }
 
+type Metadata struct {
+	// UserID is an opaque identifier for the user on whose behalf the
+	// request is made.
+	UserID string `json:"user_id,omitempty"`
+}
+
+// Message is one turn of a conversation. Its content is sent as a plain
+// string unless Blocks is set, e.g. to mark a prompt cache breakpoint.
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string
+	Content string
+	// Blocks replaces Content when set.
+	Blocks []ContentBlock
 }
 
 type Response struct {
 	Content []ContentBlock `json:"content"`
-	Error   *APIError      `json:"error,omitempty"`
+	// StopReason says why the model stopped, e.g. "end_turn", or
+	// StopMaxTokens if the reply was cut off.
+	StopReason string    `json:"stop_reason,omitempty"`
+	Usage      Usage     `json:"usage"`
+	Error      *APIError `json:"error,omitempty"`
 }
 
+// Stop reasons acted on by callers.
+const (
+	// StopMaxTokens is the stop reason of a reply cut off at the request's
+	// MaxTokens.
+	StopMaxTokens = "max_tokens"
+	// StopToolUse is the stop reason of a reply that ends in tool calls
+	// awaiting their results.
+	StopToolUse = "tool_use"
+)
+
+// Usage counts the tokens a request consumed. InputTokens excludes the
+// tokens written to or read from the prompt cache, which are billed at
+// their own rates.
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// Add adds the counts in other to u.
+func (u *Usage) Add(other Usage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheCreationInputTokens += other.CacheCreationInputTokens
+	u.CacheReadInputTokens += other.CacheReadInputTokens
+}
+
+// ContentBlock is a block of message content. Type says which of the other
+// fields are used: Text for "text" blocks; ID, Name and Input for
+// "tool_use"; ToolUseID, Content and IsError for "tool_result".
 type ContentBlock struct {
 	Type string `json:"type"`
-	Text string `json:"text"`
+	Text string `json:"text,omitempty"`
+
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+
+	// CacheControl, on a request block, caches the prompt up to and
+	// including this block.
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
+
+// ToolResult returns the block answering the tool call with ID toolUseID.
+// isError tells the model the call failed, content saying why.
+func ToolResult(toolUseID, content string, isError bool) ContentBlock {
+	return ContentBlock{Type: "tool_result", ToolUseID: toolUseID, Content: content, IsError: isError}
 }
 
+type CacheControl struct {
+	Type string `json:"type"`
+}
+
+// Ephemeral is the cache control for the default five-minute prompt
+// cache.
+var Ephemeral = &CacheControl{Type: "ephemeral"}
+
 type APIError struct {
 	Type    string `json:"type"`
 	Message string `json:"message"`
 }
+
+// StreamEvent is the data payload of one server-sent event in a streamed
+// response. Only the fields this client acts on are decoded.
+type StreamEvent struct {
+	Type  string `json:"type"`
+	Index int    `json:"index"`
+	// Message is the message so far, sent with message_start; its usage
+	// holds the input token counts.
+	Message *Response `json:"message,omitempty"`
+	Delta   *Delta    `json:"delta,omitempty"`
+	// Usage is sent with message_delta and holds the output token count.
+	Usage *Usage    `json:"usage,omitempty"`
+	Error *APIError `json:"error,omitempty"`
+}
+
+type Delta struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+	// StopReason is set in the message_delta event.
+	StopReason string `json:"stop_reason,omitempty"`
+}
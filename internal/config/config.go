@@ -0,0 +1,302 @@
+// Package config loads settings from YAML files: one for the user and one
+// kept in a repository, each with optional named profiles that bundle
+// settings for a purpose, such as a weekly triage or a release review.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LocalName is the name of the repository-local config file.
+const LocalName = ".gitissuesum.yaml"
+
+// Settings holds everything a config file or profile can set. Zero values
+// leave the setting to whatever comes before.
+type Settings struct {
+	Provider         string   `yaml:"provider,omitempty"`
+	Model            string   `yaml:"model,omitempty"`
+	BaseURL          string   `yaml:"base_url,omitempty"`
+	APIURL           string   `yaml:"api_url,omitempty"`
+	Output           string   `yaml:"output,omitempty"`
+	Template         string   `yaml:"template,omitempty"`
+	MaxIssues        int      `yaml:"max_issues,omitempty"`
+	MaxInputTokens   int      `yaml:"max_input_tokens,omitempty"`
+	MaxCost          float64  `yaml:"max_cost,omitempty"`
+	System           string   `yaml:"system,omitempty"`
+	MaxTokens        int      `yaml:"max_tokens,omitempty"`
+	MaxContinuations int      `yaml:"max_continuations,omitempty"`
+	Temperature      *float64 `yaml:"temperature,omitempty"`
+	TopP             *float64 `yaml:"top_p,omitempty"`
+	StopSequences    []string `yaml:"stop_sequences,omitempty"`
+	UserID           string   `yaml:"user_id,omitempty"`
+	PromptCache      *bool    `yaml:"prompt_cache,omitempty"`
+	IncludeComments  *bool    `yaml:"include_comments,omitempty"`
+	Repos            []string `yaml:"repos,omitempty"`
+	Filter           Filter   `yaml:"filter,omitempty"`
+	// Prices adds to or overrides the built-in model prices, keyed by
+	// model name prefix.
+	Prices map[string]Price `yaml:"prices,omitempty"`
+}
+
+// Price is a model's price in US dollars per million tokens.
+type Price struct {
+	Input      float64 `yaml:"input"`
+	Output     float64 `yaml:"output"`
+	CacheWrite float64 `yaml:"cache_write,omitempty"`
+	CacheRead  float64 `yaml:"cache_read,omitempty"`
+}
+
+// Filter mirrors the issue filter flags.
+type Filter struct {
+	State     string   `yaml:"state,omitempty"`
+	Labels    []string `yaml:"labels,omitempty"`
+	Assignee  string   `yaml:"assignee,omitempty"`
+	Author    string   `yaml:"author,omitempty"`
+	Milestone string   `yaml:"milestone,omitempty"`
+	Since     string   `yaml:"since,omitempty"`
+	Sort      string   `yaml:"sort,omitempty"`
+	Direction string   `yaml:"direction,omitempty"`
+}
+
+// File is the layout of a config file: top-level settings that always
+// apply, plus named profiles.
+type File struct {
+	Settings       `yaml:",inline"`
+	DefaultProfile string              `yaml:"default_profile,omitempty"`
+	Profiles       map[string]Settings `yaml:"profiles,omitempty"`
+}
+
+// Config is the result of merging the config files.
+type Config struct {
+	Settings
+	// Profile is the profile that was applied, if any.
+	Profile string
+	// Files lists the files that were read, lowest precedence first.
+	Files []string
+	// Ignored lists the user-only settings a repository-local file tried
+	// to set, as "file: key".
+	Ignored []string
+}
+
+// UserPath returns the user's config file: $GITISSUESUM_CONFIG, or
+// gitissuesum/config.yaml under the user config directory.
+func UserPath() (string, error) {
+	if path := os.Getenv("GITISSUESUM_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gitissuesum", "config.yaml"), nil
+}
+
+// LocalPath looks for a LocalName file in dir and its parents, stopping at
+// the root of the git repository dir is in. It returns "" if there is none.
+func LocalPath(dir string) string {
+	for {
+		path := filepath.Join(dir, LocalName)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Load merges the files at paths, lowest precedence first; missing files
+// are skipped. The top-level settings of every file apply first, then the
+// profile's settings from every file that defines it. profile defaults to
+// the last default_profile set; naming a profile no file defines is an
+// error. User-only settings in a file named LocalName are dropped and
+// listed in Ignored.
+func Load(paths []string, profile string) (*Config, error) {
+	cfg := &Config{}
+	var files []*File
+	for _, path := range paths {
+		f, err := readFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config %s: %w", path, err)
+		}
+		if filepath.Base(path) == LocalName {
+			for _, key := range f.dropUserOnly() {
+				cfg.Ignored = append(cfg.Ignored, path+": "+key)
+			}
+		}
+		files = append(files, f)
+		cfg.Files = append(cfg.Files, path)
+		cfg.Settings = cfg.Merge(f.Settings)
+		if f.DefaultProfile != "" {
+			cfg.Profile = f.DefaultProfile
+		}
+	}
+	if profile != "" {
+		cfg.Profile = profile
+	}
+	if cfg.Profile == "" {
+		return cfg, nil
+	}
+
+	found := false
+	var defined []string
+	for _, f := range files {
+		if p, ok := f.Profiles[cfg.Profile]; ok {
+			cfg.Settings = cfg.Merge(p)
+			found = true
+		}
+		for name := range f.Profiles {
+			defined = append(defined, name)
+		}
+	}
+	if !found {
+		slices.Sort(defined)
+		if len(defined) == 0 {
+			return nil, fmt.Errorf("unknown profile %q: no config file defines profiles", cfg.Profile)
+		}
+		return nil, fmt.Errorf("unknown profile %q, expected one of: %s", cfg.Profile, strings.Join(slices.Compact(defined), ", "))
+	}
+	return cfg, nil
+}
+
+func readFile(path string) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var f File
+	dec := yaml.NewDecoder(file)
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+	f.Settings.resolvePaths(dir)
+	for name, p := range f.Profiles {
+		p.resolvePaths(dir)
+		f.Profiles[name] = p
+	}
+	return &f, nil
+}
+
+// dropUserOnly clears the settings that decide where API keys and tokens
+// are sent, at the top level and in every profile, and returns the keys it
+// cleared. A repository-local file may not set them, or any repository you
+// run the tool in could point the clients at a host it controls.
+func (f *File) dropUserOnly() []string {
+	keys := f.Settings.dropUserOnly("")
+	names := slices.Sorted(maps.Keys(f.Profiles))
+	for _, name := range names {
+		p := f.Profiles[name]
+		keys = append(keys, p.dropUserOnly("profiles."+name+".")...)
+		f.Profiles[name] = p
+	}
+	return keys
+}
+
+func (s *Settings) dropUserOnly(prefix string) []string {
+	var keys []string
+	for _, v := range []struct {
+		key string
+		dst *string
+	}{
+		{"provider", &s.Provider},
+		{"base_url", &s.BaseURL},
+		{"api_url", &s.APIURL},
+	} {
+		if *v.dst != "" {
+			keys = append(keys, prefix+v.key)
+			*v.dst = ""
+		}
+	}
+	return keys
+}
+
+// resolvePaths makes a relative template path relative to the config file
+// it came from. Built-in template names have no dots or slashes.
+func (s *Settings) resolvePaths(dir string) {
+	if s.Template != "" && strings.ContainsAny(s.Template, `./\`) && !filepath.IsAbs(s.Template) {
+		s.Template = filepath.Join(dir, s.Template)
+	}
+}
+
+// Merge returns s with every setting over sets replaced.
+func (s Settings) Merge(over Settings) Settings {
+	set(&s.Provider, over.Provider)
+	set(&s.Model, over.Model)
+	set(&s.BaseURL, over.BaseURL)
+	set(&s.APIURL, over.APIURL)
+	set(&s.Output, over.Output)
+	set(&s.Template, over.Template)
+	set(&s.MaxIssues, over.MaxIssues)
+	set(&s.MaxInputTokens, over.MaxInputTokens)
+	set(&s.MaxCost, over.MaxCost)
+	set(&s.System, over.System)
+	set(&s.MaxTokens, over.MaxTokens)
+	set(&s.MaxContinuations, over.MaxContinuations)
+	set(&s.UserID, over.UserID)
+	if over.Temperature != nil {
+		s.Temperature = over.Temperature
+	}
+	if over.TopP != nil {
+		s.TopP = over.TopP
+	}
+	if over.StopSequences != nil {
+		s.StopSequences = over.StopSequences
+	}
+	if over.PromptCache != nil {
+		s.PromptCache = over.PromptCache
+	}
+	if over.IncludeComments != nil {
+		s.IncludeComments = over.IncludeComments
+	}
+	if over.Repos != nil {
+		s.Repos = over.Repos
+	}
+	if len(over.Prices) > 0 {
+		prices := make(map[string]Price, len(s.Prices)+len(over.Prices))
+		maps.Copy(prices, s.Prices)
+		maps.Copy(prices, over.Prices)
+		s.Prices = prices
+	}
+
+	f, o := &s.Filter, over.Filter
+	set(&f.State, o.State)
+	if o.Labels != nil {
+		f.Labels = o.Labels
+	}
+	set(&f.Assignee, o.Assignee)
+	set(&f.Author, o.Author)
+	set(&f.Milestone, o.Milestone)
+	set(&f.Since, o.Since)
+	set(&f.Sort, o.Sort)
+	set(&f.Direction, o.Direction)
+	return s
+}
+
+func set[T comparable](dst *T, v T) {
+	var zero T
+	if v != zero {
+		*dst = v
+	}
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, text string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	user := writeFile(t, filepath.Join(dir, "user", "config.yaml"), `
+model: user-model
+max_issues: 50
+include_comments: true
+default_profile: weekly
+profiles:
+  weekly:
+    output: json
+    filter:
+      labels: [bug]
+`)
+	local := writeFile(t, filepath.Join(dir, "repo", LocalName), `
+model: local-model
+repos: [o/r]
+filter:
+  state: all
+profiles:
+  weekly:
+    filter:
+      since: 1w
+`)
+
+	cfg, err := Load([]string{user, filepath.Join(dir, "missing.yaml"), local}, "")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Profile != "weekly" {
+		t.Errorf("Profile = %q, want the default profile", cfg.Profile)
+	}
+	if !slices.Equal(cfg.Files, []string{user, local}) {
+		t.Errorf("Files = %v", cfg.Files)
+	}
+	s := cfg.Settings
+	if s.Model != "local-model" || s.MaxIssues != 50 || s.IncludeComments == nil || !*s.IncludeComments {
+		t.Errorf("top-level settings not merged: %+v", s)
+	}
+	if s.Output != "json" || !slices.Equal(s.Filter.Labels, []string{"bug"}) || s.Filter.Since != "1w" || s.Filter.State != "all" {
+		t.Errorf("profile settings not merged: %+v", s)
+	}
+	if !slices.Equal(s.Repos, []string{"o/r"}) {
+		t.Errorf("Repos = %v", s.Repos)
+	}
+}
+
+func TestLoad_ProfileOverridesTopLevel(t *testing.T) {
+	path := writeFile(t, filepath.Join(t.TempDir(), "c.yaml"), `
+model: a
+profiles:
+  fast:
+    model: b
+`)
+	cfg, err := Load([]string{path}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Model != "a" {
+		t.Errorf("without a profile, Model = %q, want a", cfg.Model)
+	}
+	cfg, err = Load([]string{path}, "fast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Model != "b" {
+		t.Errorf("with the profile, Model = %q, want b", cfg.Model)
+	}
+}
+
+func TestLoad_RequestSettings(t *testing.T) {
+	dir := t.TempDir()
+	user := writeFile(t, filepath.Join(dir, "config.yaml"), `
+max_cost: 0.5
+prompt_cache: true
+max_continuations: 2
+temperature: 0
+stop_sequences: ["\n\n---"]
+prices:
+  llama3: {input: 0.1, output: 0.2}
+  claude-sonnet-4: {input: 3, output: 15, cache_read: 0.3}
+`)
+	local := writeFile(t, filepath.Join(dir, LocalName), `
+prices:
+  llama3: {input: 0, output: 0}
+`)
+
+	cfg, err := Load([]string{user, local}, "")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.MaxCost != 0.5 {
+		t.Errorf("MaxCost = %v, want 0.5", cfg.MaxCost)
+	}
+	if cfg.PromptCache == nil || !*cfg.PromptCache || cfg.MaxContinuations != 2 {
+		t.Errorf("prompt_cache/max_continuations = %v/%d, want true/2", cfg.PromptCache, cfg.MaxContinuations)
+	}
+	if cfg.Temperature == nil || *cfg.Temperature != 0 || len(cfg.StopSequences) != 1 {
+		t.Errorf("temperature/stop_sequences = %v/%q, want an explicit 0 kept", cfg.Temperature, cfg.StopSequences)
+	}
+	if len(cfg.Prices) != 2 || cfg.Prices["llama3"] != (Price{}) || cfg.Prices["claude-sonnet-4"].CacheRead != 0.3 {
+		t.Errorf("Prices = %+v, want the local llama3 price merged over the user's", cfg.Prices)
+	}
+}
+
+func TestLoad_LocalCannotSetEndpoints(t *testing.T) {
+	dir := t.TempDir()
+	user := writeFile(t, filepath.Join(dir, "config.yaml"), `
+base_url: https://llm.example.com
+profiles:
+  ghes:
+    api_url: https://ghe.example.com/api/v3
+`)
+	local := writeFile(t, filepath.Join(dir, "repo", LocalName), `
+provider: openai
+base_url: https://evil.example
+model: local-model
+profiles:
+  ghes:
+    api_url: https://evil.example/api/v3
+`)
+
+	cfg, err := Load([]string{user, local}, "ghes")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Provider != "" || cfg.BaseURL != "https://llm.example.com" || cfg.APIURL != "https://ghe.example.com/api/v3" {
+		t.Errorf("provider/base_url/api_url = %q/%q/%q, want the user's", cfg.Provider, cfg.BaseURL, cfg.APIURL)
+	}
+	if cfg.Model != "local-model" {
+		t.Errorf("Model = %q, other local settings should still apply", cfg.Model)
+	}
+	want := []string{local + ": provider", local + ": base_url", local + ": profiles.ghes.api_url"}
+	if !slices.Equal(cfg.Ignored, want) {
+		t.Errorf("Ignored = %q, want %q", cfg.Ignored, want)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, filepath.Join(dir, "c.yaml"), "profiles:\n  a: {}\n  b: {}\n")
+
+	if _, err := Load([]string{path}, "c"); err == nil || !strings.Contains(err.Error(), "expected one of: a, b") {
+		t.Errorf("unknown profile error = %v", err)
+	}
+	if _, err := Load(nil, "c"); err == nil {
+		t.Error("Load() accepted a profile with no config files")
+	}
+
+	typo := writeFile(t, filepath.Join(dir, "typo.yaml"), "modle: x\n")
+	if _, err := Load([]string{typo}, ""); err == nil || !strings.Contains(err.Error(), "modle") {
+		t.Errorf("unknown field error = %v", err)
+	}
+
+	empty := writeFile(t, filepath.Join(dir, "empty.yaml"), "")
+	if _, err := Load([]string{empty}, ""); err != nil {
+		t.Errorf("empty file: %v", err)
+	}
+}
+
+func TestLoad_TemplatePaths(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, filepath.Join(dir, "c.yaml"), `
+template: prompts/weekly.tmpl
+profiles:
+  exec:
+    template: executive
+  abs:
+    template: /etc/gitissuesum/t.tmpl
+`)
+	tests := map[string]string{
+		"":     filepath.Join(dir, "prompts", "weekly.tmpl"),
+		"exec": "executive",
+		"abs":  "/etc/gitissuesum/t.tmpl",
+	}
+	for profile, want := range tests {
+		cfg, err := Load([]string{path}, profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Template != want {
+			t.Errorf("profile %q: Template = %q, want %q", profile, cfg.Template, want)
+		}
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "repo")
+	sub := filepath.Join(repo, "a", "b")
+	if err := os.MkdirAll(sub, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	// Outside the repository, so never used.
+	writeFile(t, filepath.Join(root, LocalName), "")
+
+	if got := LocalPath(sub); got != "" {
+		t.Errorf("LocalPath() = %q, should stop at the git root", got)
+	}
+
+	want := writeFile(t, filepath.Join(repo, LocalName), "")
+	if got := LocalPath(sub); got != want {
+		t.Errorf("LocalPath() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package duplicates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+	"github.com/mrphil/gitissuesum/internal/truncate"
+)
+
+// maxPromptBodyChars caps each issue body shown to the model.
+const maxPromptBodyChars = 800
+
+const confirmInstructions = `Decide which of these issues report the same underlying problem or request.
+Respond with a single JSON object and nothing else (no prose, no code fences), matching this schema:
+{
+  "duplicates": [issue numbers],  // the issues that are duplicates of each other; empty if none are
+  "canonical": issue number,       // the one to keep open, from "duplicates"
+  "confidence": number,            // 0 to 1
+  "reason": string                 // one sentence
+}
+
+Leave out issues that are only related, not duplicates.`
+
+// verdict is the model's answer for one cluster.
+type verdict struct {
+	Duplicates []int   `json:"duplicates"`
+	Canonical  int     `json:"canonical"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
+
+// Confirm asks the model to check each cluster, keeping only the issues it
+// agrees are duplicates and taking its canonical pick and confidence.
+// Clusters it rejects are dropped.
+func Confirm(ctx context.Context, opts summarize.Options, issues []summarize.Issue, clusters []Cluster, logf func(string, ...any)) ([]Cluster, error) {
+	byNumber := make(map[int]summarize.Issue, len(issues))
+	index := make(map[int]int, len(issues))
+	for i, issue := range issues {
+		byNumber[issue.Number] = issue
+		index[issue.Number] = i
+	}
+	vectors := vectorize(issues)
+
+	confirmed := []Cluster{}
+	for i, c := range clusters {
+		logf("Confirming cluster %d/%d (%d issues)...\n", i+1, len(clusters), len(c.Duplicates)+1)
+		resp, err := opts.Provider.Send(ctx, summarize.NewRequest(opts, buildConfirmPrompt(c, byNumber)))
+		if err != nil {
+			return nil, fmt.Errorf("cluster %d/%d: %w", i+1, len(clusters), err)
+		}
+		v, err := parseVerdict(resp.Text(), c)
+		if err != nil {
+			logf("Skipping cluster %d/%d: %v\n", i+1, len(clusters), err)
+			continue
+		}
+		if len(v.Duplicates) < 2 {
+			continue
+		}
+		members := make([]int, len(v.Duplicates))
+		for j, n := range v.Duplicates {
+			members[j] = index[n]
+		}
+		c = newCluster(issues, vectors, members, index[v.Canonical])
+		c.Confidence = round(v.Confidence)
+		c.Reason = strings.TrimSpace(v.Reason)
+		confirmed = append(confirmed, c)
+	}
+	return confirmed, nil
+}
+
+func buildConfirmPrompt(c Cluster, byNumber map[int]summarize.Issue) string {
+	var b strings.Builder
+
+	b.WriteString("These issues were flagged as possible duplicates by text similarity.\n\n")
+	for _, n := range c.numbers() {
+		issue := byNumber[n]
+		fmt.Fprintf(&b, "--- Issue #%d ---\n", n)
+		fmt.Fprintf(&b, "Title: %s\n", issue.Title)
+		if body := truncate.String(issue.Body, maxPromptBodyChars); body != "" {
+			fmt.Fprintf(&b, "Body: %s\n", body)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(confirmInstructions)
+
+	return b.String()
+}
+
+// parseVerdict decodes the model's reply and checks it only refers to the
+// cluster's issues.
+func parseVerdict(reply string, c Cluster) (verdict, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return verdict{}, errors.New("no JSON object in reply")
+	}
+	var v verdict
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &v); err != nil {
+		return verdict{}, fmt.Errorf("malformed reply: %w", err)
+	}
+
+	slices.Sort(v.Duplicates)
+	v.Duplicates = slices.Compact(v.Duplicates)
+	numbers := c.numbers()
+	for _, n := range v.Duplicates {
+		if !slices.Contains(numbers, n) {
+			return verdict{}, fmt.Errorf("reply references issue #%d outside the cluster", n)
+		}
+	}
+	if len(v.Duplicates) > 0 && !slices.Contains(v.Duplicates, v.Canonical) {
+		return verdict{}, fmt.Errorf("canonical issue #%d is not among the duplicates", v.Canonical)
+	}
+	v.Confidence = min(max(v.Confidence, 0), 1)
+	return v, nil
+}
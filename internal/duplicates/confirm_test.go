@@ -0,0 +1,81 @@
+package duplicates
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+// fakeProvider replies with canned responses in order and records the
+// prompts it was sent.
+type fakeProvider struct {
+	replies []string
+	prompts []string
+}
+
+func (f *fakeProvider) Send(ctx context.Context, req claude.Request) (*claude.Response, error) {
+	f.prompts = append(f.prompts, req.Messages[len(req.Messages)-1].Content)
+	reply := "{}"
+	if len(f.replies) > 0 {
+		reply, f.replies = f.replies[0], f.replies[1:]
+	}
+	return &claude.Response{Content: []claude.ContentBlock{{Type: "text", Text: reply}}}, nil
+}
+
+func (f *fakeProvider) Stream(ctx context.Context, req claude.Request, onText func(string)) (*claude.Response, error) {
+	return f.Send(ctx, req)
+}
+
+func nologf(string, ...any) {}
+
+func TestConfirm_NarrowsCluster(t *testing.T) {
+	issues := testIssues()
+	clusters := Find(issues, 0.4)
+	fake := &fakeProvider{replies: []string{
+		"```json\n" + `{"duplicates": [1, 5], "canonical": 1, "confidence": 0.9, "reason": "Both report the startup segfault."}` + "\n```",
+	}}
+
+	got, err := Confirm(context.Background(), summarize.Options{Provider: fake, Model: "m"}, issues, clusters, nologf)
+	if err != nil {
+		t.Fatalf("Confirm() error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d clusters, want 1", len(got))
+	}
+	c := got[0]
+	if c.Canonical.Number != 1 || len(c.Duplicates) != 1 || c.Duplicates[0].Number != 5 {
+		t.Errorf("cluster = %+v, want #1 keeping #5", c)
+	}
+	if c.Confidence != 0.9 || c.Reason == "" {
+		t.Errorf("confidence/reason = %v/%q, want the model's", c.Confidence, c.Reason)
+	}
+	if !strings.Contains(fake.prompts[0], "Issue #3") {
+		t.Errorf("prompt should list the cluster's issues, got:\n%s", fake.prompts[0])
+	}
+}
+
+func TestConfirm_DropsRejected(t *testing.T) {
+	issues := testIssues()
+	fake := &fakeProvider{replies: []string{`{"duplicates": [], "canonical": 0, "confidence": 0.1, "reason": "Different crashes."}`}}
+
+	got, err := Confirm(context.Background(), summarize.Options{Provider: fake, Model: "m"}, issues, Find(issues, 0.4), nologf)
+	if err != nil {
+		t.Fatalf("Confirm() error: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d clusters, want the rejected one dropped", len(got))
+	}
+}
+
+func TestParseVerdict_OutsideCluster(t *testing.T) {
+	c := Cluster{Canonical: Member{Number: 1}, Duplicates: []Member{{Number: 2}}}
+	if _, err := parseVerdict(`{"duplicates": [1, 9], "canonical": 1}`, c); err == nil {
+		t.Error("expected error for an issue outside the cluster")
+	}
+	if _, err := parseVerdict(`{"duplicates": [1, 2], "canonical": 3}`, c); err == nil {
+		t.Error("expected error for a canonical issue outside the duplicates")
+	}
+}
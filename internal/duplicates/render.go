@@ -0,0 +1,38 @@
+package duplicates
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+// Report is the result of a duplicates run, as written with --output json.
+type Report struct {
+	Repository string    `json:"repository"`
+	IssueCount int       `json:"issue_count"`
+	Threshold  float64   `json:"threshold"`
+	Confirmed  bool      `json:"confirmed"`
+	Clusters   []Cluster `json:"clusters"`
+	// Usage is the token usage of --confirm, when it ran.
+	Usage *summarize.UsageReport `json:"usage,omitempty"`
+}
+
+func WriteText(w io.Writer, r Report) error {
+	if len(r.Clusters) == 0 {
+		_, err := fmt.Fprintf(w, "No likely duplicates among %d issues in %s.\n", r.IssueCount, r.Repository)
+		return err
+	}
+
+	fmt.Fprintf(w, "%d clusters of likely duplicates among %d issues in %s:\n", len(r.Clusters), r.IssueCount, r.Repository)
+	for i, c := range r.Clusters {
+		fmt.Fprintf(w, "\n%d. Keep #%d %s (confidence %.2f)\n", i+1, c.Canonical.Number, c.Canonical.Title, c.Confidence)
+		if c.Reason != "" {
+			fmt.Fprintf(w, "   %s\n", c.Reason)
+		}
+		for _, m := range c.Duplicates {
+			fmt.Fprintf(w, "   - #%d %s (similarity %.2f)\n", m.Number, m.Title, m.Similarity)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+// Package duplicates groups issues that likely report the same problem.
+// A local TF-IDF comparison of titles and bodies proposes clusters, which a
+// model can optionally confirm.
+package duplicates
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+	"github.com/mrphil/gitissuesum/internal/truncate"
+)
+
+// maxBodyChars limits how much of each body is compared, so long logs and
+// templates don't drown out the description.
+const maxBodyChars = 2000
+
+// titleWeight counts title words several times, since titles are the most
+// telling part of a duplicate.
+const titleWeight = 2
+
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`a an and are as at be but by can do does for from has have i if in
+		into is it its me my no not of on or our so that the their then there this to too was we were
+		what when where which while who will with would you your`) {
+		stopWords[w] = true
+	}
+}
+
+type Cluster struct {
+	// Canonical is the issue the others should be closed in favor of.
+	Canonical Member `json:"canonical"`
+	// Duplicates are the other issues, each with its similarity to the
+	// canonical one.
+	Duplicates []Member `json:"duplicates"`
+	// Confidence runs from 0 to 1. Without confirmation it is the mean
+	// similarity of the duplicates to the canonical issue.
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason,omitempty"`
+}
+
+type Member struct {
+	Number     int     `json:"number"`
+	Title      string  `json:"title"`
+	URL        string  `json:"url,omitempty"`
+	Similarity float64 `json:"similarity"`
+}
+
+func (c Cluster) numbers() []int {
+	numbers := []int{c.Canonical.Number}
+	for _, m := range c.Duplicates {
+		numbers = append(numbers, m.Number)
+	}
+	return numbers
+}
+
+// Find clusters issues whose pairwise cosine similarity reaches threshold,
+// linking clusters transitively. Clusters are ordered by confidence.
+func Find(issues []summarize.Issue, threshold float64) []Cluster {
+	vectors := vectorize(issues)
+
+	parent := make([]int, len(issues))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range issues {
+		for j := i + 1; j < len(issues); j++ {
+			if cosine(vectors[i], vectors[j]) >= threshold {
+				parent[root(j)] = root(i)
+			}
+		}
+	}
+
+	groups := map[int][]int{}
+	for i := range issues {
+		r := root(i)
+		groups[r] = append(groups[r], i)
+	}
+
+	clusters := []Cluster{}
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		clusters = append(clusters, newCluster(issues, vectors, members, pickCanonical(issues, members)))
+	}
+	slices.SortFunc(clusters, func(a, b Cluster) int {
+		if c := cmp.Compare(b.Confidence, a.Confidence); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Canonical.Number, b.Canonical.Number)
+	})
+	return clusters
+}
+
+// pickCanonical prefers the most discussed issue, falling back to the
+// oldest, since that is where the context already lives.
+func pickCanonical(issues []summarize.Issue, members []int) int {
+	return slices.MinFunc(members, func(a, b int) int {
+		if c := cmp.Compare(issues[b].Comments, issues[a].Comments); c != 0 {
+			return c
+		}
+		if c := issues[a].CreatedAt.Compare(issues[b].CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(issues[a].Number, issues[b].Number)
+	})
+}
+
+// newCluster builds the cluster of members, indexes into issues, around the
+// canonical one.
+func newCluster(issues []summarize.Issue, vectors []vector, members []int, canonical int) Cluster {
+	c := Cluster{Canonical: member(issues[canonical], 1)}
+	total := 0.0
+	for _, i := range members {
+		if i == canonical {
+			continue
+		}
+		sim := cosine(vectors[canonical], vectors[i])
+		c.Duplicates = append(c.Duplicates, member(issues[i], sim))
+		total += sim
+	}
+	slices.SortFunc(c.Duplicates, func(a, b Member) int {
+		return cmp.Compare(b.Similarity, a.Similarity)
+	})
+	c.Confidence = round(total / float64(len(c.Duplicates)))
+	return c
+}
+
+func member(issue summarize.Issue, similarity float64) Member {
+	return Member{Number: issue.Number, Title: issue.Title, URL: issue.URL, Similarity: round(similarity)}
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// vector is a sparse TF-IDF vector normalized to unit length.
+type vector map[string]float64
+
+func vectorize(issues []summarize.Issue) []vector {
+	counts := make([]map[string]int, len(issues))
+	df := map[string]int{}
+	for i, issue := range issues {
+		counts[i] = map[string]int{}
+		for range titleWeight {
+			for _, t := range tokenize(issue.Title) {
+				counts[i][t]++
+			}
+		}
+		for _, t := range tokenize(truncate.String(issue.Body, maxBodyChars)) {
+			counts[i][t]++
+		}
+		for t := range counts[i] {
+			df[t]++
+		}
+	}
+
+	n := float64(len(issues))
+	vectors := make([]vector, len(issues))
+	for i, c := range counts {
+		v := vector{}
+		norm := 0.0
+		for t, tf := range c {
+			// Terms found in every issue, like template headings, get no
+			// weight at all.
+			w := (1 + math.Log(float64(tf))) * math.Log(n/float64(df[t]))
+			if w <= 0 {
+				continue
+			}
+			v[t] = w
+			norm += w * w
+		}
+		norm = math.Sqrt(norm)
+		for t := range v {
+			v[t] /= norm
+		}
+		vectors[i] = v
+	}
+	return vectors
+}
+
+func cosine(a, b vector) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	dot := 0.0
+	for t, w := range a {
+		dot += w * b[t]
+	}
+	return dot
+}
+
+// tokenize lowercases s and splits it into stemmed words, dropping stop
+// words and single characters.
+func tokenize(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := words[:0]
+	for _, w := range words {
+		if len(w) > 1 && !stopWords[w] {
+			tokens = append(tokens, stem(w))
+		}
+	}
+	return tokens
+}
+
+// stem strips a few common English suffixes so that "crashes", "crashed"
+// and "crashing" all match "crash". It is deliberately crude: both sides of
+// a comparison are stemmed the same way, which is all that matters here.
+func stem(w string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if len(w) > len(suffix)+2 && strings.HasSuffix(w, suffix) && !strings.HasSuffix(w, "ss") {
+			return strings.TrimSuffix(w, suffix)
+		}
+	}
+	return w
+}
@@ -0,0 +1,84 @@
+package duplicates
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+func testIssues() []summarize.Issue {
+	day := func(n int) time.Time { return time.Date(2025, 1, n, 0, 0, 0, 0, time.UTC) }
+	return []summarize.Issue{
+		{Number: 1, Title: "App crashes on startup with segfault", Body: "Launching the app segfaults immediately on macOS.", CreatedAt: day(1)},
+		{Number: 2, Title: "Dark mode for settings page", Body: "Please add a dark theme to the settings screen.", CreatedAt: day(2)},
+		{Number: 3, Title: "Segfault on startup", Body: "The app crashes with a segfault right after launching.", Comments: 4, CreatedAt: day(3)},
+		{Number: 4, Title: "Export to CSV", Body: "It would help to export reports as CSV files.", CreatedAt: day(4)},
+		{Number: 5, Title: "Crash at startup (segfault)", Body: "Segfault when launching since the last update.", CreatedAt: day(5)},
+		{Number: 6, Title: "Typo in README", Body: "The install section says isntall.", CreatedAt: day(6)},
+		{Number: 7, Title: "Support proxies", Body: "Requests ignore the HTTPS_PROXY variable.", CreatedAt: day(7)},
+		{Number: 8, Title: "Slow search", Body: "Searching large projects takes minutes.", CreatedAt: day(8)},
+		{Number: 9, Title: "Keyboard shortcuts", Body: "Add a shortcut to open the command palette.", CreatedAt: day(9)},
+		{Number: 10, Title: "Translate to German", Body: "The interface is only available in English.", CreatedAt: day(10)},
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("The app's UI is BROKEN, see #123!")
+	want := []string{"app", "ui", "broken", "see", "123"}
+	if !slices.Equal(got, want) {
+		t.Errorf("tokenize() = %v, want %v", got, want)
+	}
+}
+
+func TestFind_GroupsDuplicates(t *testing.T) {
+	clusters := Find(testIssues(), 0.4)
+
+	if len(clusters) != 1 {
+		t.Fatalf("got %d clusters, want 1: %+v", len(clusters), clusters)
+	}
+	c := clusters[0]
+	if c.Canonical.Number != 3 {
+		t.Errorf("canonical = #%d, want #3, the most discussed", c.Canonical.Number)
+	}
+	got := c.numbers()
+	slices.Sort(got)
+	if !slices.Equal(got, []int{1, 3, 5}) {
+		t.Errorf("cluster = %v, want [1 3 5]", got)
+	}
+	if c.Confidence < 0.4 || c.Confidence > 1 {
+		t.Errorf("confidence = %v, want between threshold and 1", c.Confidence)
+	}
+}
+
+func TestFind_CanonicalFallsBackToOldest(t *testing.T) {
+	issues := testIssues()
+	issues[2].Comments = 0
+
+	clusters := Find(issues, 0.4)
+	if len(clusters) != 1 || clusters[0].Canonical.Number != 1 {
+		t.Errorf("canonical should be the oldest issue, got %+v", clusters)
+	}
+}
+
+func TestFind_HighThreshold(t *testing.T) {
+	if clusters := Find(testIssues(), 1); len(clusters) != 0 {
+		t.Errorf("got %d clusters at threshold 1, want 0", len(clusters))
+	}
+}
+
+func TestCosine_IdenticalIssues(t *testing.T) {
+	issues := []summarize.Issue{
+		{Number: 1, Title: "Login fails"},
+		{Number: 2, Title: "Login fails"},
+		{Number: 3, Title: "Unrelated feature"},
+	}
+	v := vectorize(issues)
+	if got := cosine(v[0], v[1]); got < 0.99 {
+		t.Errorf("cosine of identical issues = %v, want 1", got)
+	}
+	if got := cosine(v[0], v[2]); got != 0 {
+		t.Errorf("cosine of unrelated issues = %v, want 0", got)
+	}
+}
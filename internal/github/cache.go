@@ -0,0 +1,174 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// cache holds GitHub responses between runs. It is nil, and caching is
+// off, until SetCache is called.
+var cache *Cache
+
+func SetCache(c *Cache) {
+	cache = c
+}
+
+// Cache stores response bodies on disk, one file per request URL, together
+// with the validators needed to revalidate them. Entries are never served
+// without asking GitHub first; a 304 reply just saves the download and does
+// not count against the rate limit.
+type Cache struct {
+	Dir string
+}
+
+type cacheEntry struct {
+	URL          string          `json:"url"`
+	ETag         string          `json:"etag,omitempty"`
+	LastModified string          `json:"last_modified,omitempty"`
+	Link         string          `json:"link,omitempty"`
+	StoredAt     time.Time       `json:"stored_at"`
+	Body         json.RawMessage `json:"body"`
+}
+
+type CacheEntry struct {
+	URL      string
+	Size     int64
+	StoredAt time.Time
+}
+
+func NewCache(dir string) *Cache {
+	return &Cache{Dir: dir}
+}
+
+func (c *Cache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load returns the entry for url, or nil if there is none. Unreadable
+// entries are treated as missing so a damaged cache never breaks a run.
+func (c *Cache) load(url string) *cacheEntry {
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if json.Unmarshal(data, &e) != nil || e.URL != url {
+		return nil
+	}
+	return &e
+}
+
+func (c *Cache) store(url string, header http.Header, body []byte) error {
+	if !json.Valid(body) {
+		return errors.New("response body is not JSON")
+	}
+	e := cacheEntry{
+		URL:          url,
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+		Link:         header.Get("Link"),
+		StoredAt:     time.Now().UTC(),
+		Body:         body,
+	}
+	if e.ETag == "" && e.LastModified == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.Dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(url))
+}
+
+func (c *Cache) setConditionalHeaders(req *http.Request, e *cacheEntry) {
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+// Entries lists the cached responses, oldest first.
+func (c *Cache) Entries() ([]CacheEntry, error) {
+	files, err := c.files()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []CacheEntry
+	for _, name := range files {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		var e cacheEntry
+		if json.Unmarshal(data, &e) != nil {
+			continue
+		}
+		entries = append(entries, CacheEntry{URL: e.URL, Size: int64(len(data)), StoredAt: e.StoredAt})
+	}
+	slices.SortFunc(entries, func(a, b CacheEntry) int {
+		return a.StoredAt.Compare(b.StoredAt)
+	})
+	return entries, nil
+}
+
+// Clear removes every cached response and returns how many were removed.
+func (c *Cache) Clear() (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+	for i, name := range files {
+		if err := os.Remove(name); err != nil {
+			return i, err
+		}
+	}
+	return len(files), nil
+}
+
+func (c *Cache) files() ([]string, error) {
+	dirEntries, err := os.ReadDir(c.Dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, d := range dirEntries {
+		if d.Type().IsRegular() && strings.HasSuffix(d.Name(), ".json") {
+			files = append(files, filepath.Join(c.Dir, d.Name()))
+		}
+	}
+	return files, nil
+}
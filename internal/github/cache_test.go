@@ -0,0 +1,152 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func withCache(t *testing.T) *Cache {
+	t.Helper()
+	c := NewCache(t.TempDir())
+	old := cache
+	SetCache(c)
+	t.Cleanup(func() { SetCache(old) })
+	return c
+}
+
+func TestFetchIssues_CacheRevalidates(t *testing.T) {
+	c := withCache(t)
+	requests := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if requests > 1 {
+			t.Errorf("second request missing If-None-Match, headers: %v", r.Header)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		json.NewEncoder(w).Encode([]Issue{{Number: 1, Title: "cached"}})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	for i := range 2 {
+		got, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 100)
+		if err != nil {
+			t.Fatalf("run %d: FetchIssues() error: %v", i+1, err)
+		}
+		if len(got) != 1 || got[0].Title != "cached" {
+			t.Errorf("run %d: unexpected issues: %v", i+1, got)
+		}
+	}
+
+	entries, err := c.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d cache entries, want 1", len(entries))
+	}
+}
+
+func TestFetchIssues_CacheKeepsPagination(t *testing.T) {
+	withCache(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", "Mon, 02 Jun 2025 10:00:00 GMT")
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode([]Issue{{Number: 2}})
+			return
+		}
+		w.Header().Set("Link", `<http://`+r.Host+`/repos/o/r/issues?page=2>; rel="next"`)
+		json.NewEncoder(w).Encode([]Issue{{Number: 1}})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	for i := range 2 {
+		got, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 100)
+		if err != nil {
+			t.Fatalf("run %d: FetchIssues() error: %v", i+1, err)
+		}
+		if len(got) != 2 {
+			t.Errorf("run %d: got %d issues, want 2", i+1, len(got))
+		}
+	}
+}
+
+func TestFetchIssues_NoValidatorsNotCached(t *testing.T) {
+	c := withCache(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]Issue{{Number: 1}})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	if _, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 100); err != nil {
+		t.Fatalf("FetchIssues() error: %v", err)
+	}
+	if entries, _ := c.Entries(); len(entries) != 0 {
+		t.Errorf("got %d cache entries for a response without validators, want 0", len(entries))
+	}
+}
+
+func TestCache_CorruptEntryIgnored(t *testing.T) {
+	c := NewCache(t.TempDir())
+	if err := os.WriteFile(c.path("http://x/a"), []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if e := c.load("http://x/a"); e != nil {
+		t.Errorf("load() of corrupt entry = %v, want nil", e)
+	}
+}
+
+func TestCache_Clear(t *testing.T) {
+	c := NewCache(filepath.Join(t.TempDir(), "cache"))
+	header := http.Header{"Etag": []string{`"x"`}}
+	for _, url := range []string{"http://x/a", "http://x/b"} {
+		if err := c.store(url, header, []byte(`[]`)); err != nil {
+			t.Fatalf("store(%s) error: %v", url, err)
+		}
+	}
+
+	n, err := c.Clear()
+	if err != nil {
+		t.Fatalf("Clear() error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Clear() removed %d entries, want 2", n)
+	}
+	if entries, _ := c.Entries(); len(entries) != 0 {
+		t.Errorf("got %d entries after Clear(), want 0", len(entries))
+	}
+}
+
+func TestCache_ClearMissingDir(t *testing.T) {
+	c := NewCache(filepath.Join(t.TempDir(), "missing"))
+	if n, err := c.Clear(); err != nil || n != 0 {
+		t.Errorf("Clear() on missing dir = %d, %v; want 0, nil", n, err)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 	"time"
@@ -16,12 +18,19 @@ var (
 	httpClient = &http.Client{Timeout: 30 * time.Second}
 )
 
-func FetchIssues(ctx context.Context, owner, repo, token string, maxIssues int) ([]Issue, error) {
-	url := fmt.Sprintf("%s/repos/%s/%s/issues?state=open&per_page=100", baseURL, owner, repo)
+// SetBaseURL points the client at another REST API root, such as a GitHub
+// Enterprise Server's https://host/api/v3.
+func SetBaseURL(u string) {
+	baseURL = strings.TrimSuffix(u, "/")
+}
+
+func FetchIssues(ctx context.Context, owner, repo, token string, filter IssueFilter, maxIssues int) ([]Issue, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/issues?%s", baseURL, owner, repo, filter.query().Encode())
 
 	var allIssues []Issue
 	for url != "" && len(allIssues) < maxIssues {
-		issues, nextURL, err := fetchPage(ctx, url, token)
+		var issues []Issue
+		nextURL, err := fetchPage(ctx, url, token, &issues)
 		if err != nil {
 			return nil, err
 		}
@@ -40,10 +49,43 @@ func FetchIssues(ctx context.Context, owner, repo, token string, maxIssues int)
 	return allIssues, nil
 }
 
-func fetchPage(ctx context.Context, url, token string) ([]Issue, string, error) {
+func (f IssueFilter) query() url.Values {
+	q := url.Values{}
+	q.Set("state", "open")
+	if f.State != "" {
+		q.Set("state", f.State)
+	}
+	if len(f.Labels) > 0 {
+		q.Set("labels", strings.Join(f.Labels, ","))
+	}
+	if f.Assignee != "" {
+		q.Set("assignee", f.Assignee)
+	}
+	if f.Creator != "" {
+		q.Set("creator", f.Creator)
+	}
+	if f.Milestone != "" {
+		q.Set("milestone", f.Milestone)
+	}
+	if !f.Since.IsZero() {
+		q.Set("since", f.Since.UTC().Format(time.RFC3339))
+	}
+	if f.Sort != "" {
+		q.Set("sort", f.Sort)
+	}
+	if f.Direction != "" {
+		q.Set("direction", f.Direction)
+	}
+	q.Set("per_page", "100")
+	return q
+}
+
+// fetchPage decodes one page of a list endpoint into v and returns the URL
+// of the next page, if any.
+func fetchPage(ctx context.Context, url, token string, v any) (string, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, "", err
+		return "", err
 	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("User-Agent", "gitissuesum")
@@ -51,46 +93,99 @@ func fetchPage(ctx context.Context, url, token string) ([]Issue, string, error)
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
+	var cached *cacheEntry
+	if cache != nil {
+		if cached = cache.load(url); cached != nil {
+			cache.setConditionalHeaders(req, cached)
+		}
+	}
+
 	resp, err := doWithRetry(req)
 	if err != nil {
-		return nil, "", fmt.Errorf("GitHub API request failed: %w", err)
+		return "", fmt.Errorf("GitHub API request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		if err := json.Unmarshal(cached.Body, v); err != nil {
+			return "", fmt.Errorf("failed to decode cached GitHub response: %w", err)
+		}
+		return parseNextLink(cached.Link), nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("GitHub API returned status %d for %s", resp.StatusCode, url)
+		return "", statusError(resp, url)
 	}
 
-	var issues []Issue
-	if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
-		return nil, "", fmt.Errorf("failed to decode GitHub response: %w", err)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read GitHub response: %w", err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return "", fmt.Errorf("failed to decode GitHub response: %w", err)
+	}
+	if cache != nil {
+		// A failed write only costs a full download next time.
+		_ = cache.store(url, resp.Header, body)
 	}
 
-	nextURL := parseNextLink(resp.Header.Get("Link"))
-	return issues, nextURL, nil
+	return parseNextLink(resp.Header.Get("Link")), nil
 }
 
 func doWithRetry(req *http.Request) (*http.Response, error) {
-	backoff := []time.Duration{0, 1 * time.Second, 2 * time.Second}
-	var resp *http.Response
-	var err error
-	for i, wait := range backoff {
-		if i > 0 {
-			time.Sleep(wait)
-		}
-		resp, err = httpClient.Do(req)
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		resp, err := httpClient.Do(req)
 		if err != nil {
+			if ctx.Err() != nil || attempt == maxRetries {
+				return nil, err
+			}
+			if err := sleepContext(ctx, retryBackoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		recordRateLimit(resp)
+
+		if wait, limited := rateLimitWait(resp, attempt, time.Now()); limited {
+			if attempt == maxRetries || wait > maxRateLimitWait {
+				return resp, nil
+			}
+			resp.Body.Close()
+			fmt.Fprintf(logOutput, "GitHub rate limit reached, waiting %s before retrying...\n", wait.Round(time.Second))
+			if err := sleepContext(ctx, wait); err != nil {
+				return nil, err
+			}
 			continue
 		}
+
 		switch resp.StatusCode {
-		case http.StatusTooManyRequests, http.StatusBadGateway,
-			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			if attempt == maxRetries {
+				return resp, nil
+			}
 			resp.Body.Close()
+			if err := sleepContext(ctx, retryBackoff(attempt)); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		return resp, nil
 	}
-	return resp, err
+}
+
+func retryBackoff(attempt int) time.Duration {
+	return time.Second << attempt
+}
+
+func statusError(resp *http.Response, url string) error {
+	msg := fmt.Sprintf("GitHub API returned status %d for %s", resp.StatusCode, url)
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if rl, ok := parseRateLimit(resp.Header); ok && rl.Remaining == 0 {
+			msg += fmt.Sprintf(": rate limit exceeded, resets at %s", rl.Reset.Local().Format(time.TimeOnly))
+		}
+	}
+	return fmt.Errorf("%s", msg)
 }
 
 func parseNextLink(header string) string {
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestParseNextLink_Empty(t *testing.T) {
@@ -54,7 +55,7 @@ func TestFetchIssues_Basic(t *testing.T) {
 	baseURL = srv.URL
 	defer func() { baseURL = old }()
 
-	got, err := FetchIssues(context.Background(), "o", "r", "", 100)
+	got, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 100)
 	if err != nil {
 		t.Fatalf("FetchIssues() error: %v", err)
 	}
@@ -82,7 +83,7 @@ func TestFetchIssues_FiltersPRs(t *testing.T) {
 	baseURL = srv.URL
 	defer func() { baseURL = old }()
 
-	got, err := FetchIssues(context.Background(), "o", "r", "", 100)
+	got, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 100)
 	if err != nil {
 		t.Fatalf("FetchIssues() error: %v", err)
 	}
@@ -114,7 +115,7 @@ func TestFetchIssues_Pagination(t *testing.T) {
 	baseURL = srv.URL
 	defer func() { baseURL = old }()
 
-	got, err := FetchIssues(context.Background(), "o", "r", "", 100)
+	got, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 100)
 	if err != nil {
 		t.Fatalf("FetchIssues() error: %v", err)
 	}
@@ -142,7 +143,7 @@ func TestFetchIssues_MaxIssuesLimit(t *testing.T) {
 	baseURL = srv.URL
 	defer func() { baseURL = old }()
 
-	got, err := FetchIssues(context.Background(), "o", "r", "", 2)
+	got, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 2)
 	if err != nil {
 		t.Fatalf("FetchIssues() error: %v", err)
 	}
@@ -161,7 +162,7 @@ func TestFetchIssues_ErrorStatus(t *testing.T) {
 	baseURL = srv.URL
 	defer func() { baseURL = old }()
 
-	_, err := FetchIssues(context.Background(), "o", "r", "", 100)
+	_, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 100)
 	if err == nil {
 		t.Fatal("expected error for 404 status")
 	}
@@ -180,8 +181,95 @@ func TestFetchIssues_AuthHeader(t *testing.T) {
 	baseURL = srv.URL
 	defer func() { baseURL = old }()
 
-	_, err := FetchIssues(context.Background(), "o", "r", "test-token", 100)
+	_, err := FetchIssues(context.Background(), "o", "r", "test-token", IssueFilter{}, 100)
 	if err != nil {
 		t.Fatalf("FetchIssues() error: %v", err)
 	}
 }
+
+func TestFetchIssues_DefaultQuery(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if got := q.Get("state"); got != "open" {
+			t.Errorf("state = %q, want 'open'", got)
+		}
+		if got := q.Get("per_page"); got != "100" {
+			t.Errorf("per_page = %q, want '100'", got)
+		}
+		for _, param := range []string{"labels", "assignee", "creator", "milestone", "since", "sort", "direction"} {
+			if q.Has(param) {
+				t.Errorf("unexpected %s parameter for empty filter", param)
+			}
+		}
+		json.NewEncoder(w).Encode([]Issue{})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	if _, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 100); err != nil {
+		t.Fatalf("FetchIssues() error: %v", err)
+	}
+}
+
+func TestFetchIssues_FilterQuery(t *testing.T) {
+	filter := IssueFilter{
+		State:     "all",
+		Labels:    []string{"bug", "help wanted"},
+		Assignee:  "none",
+		Creator:   "alice",
+		Milestone: "3",
+		Since:     time.Date(2025, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600)),
+		Sort:      "updated",
+		Direction: "asc",
+	}
+	want := map[string]string{
+		"state":     "all",
+		"labels":    "bug,help wanted",
+		"assignee":  "none",
+		"creator":   "alice",
+		"milestone": "3",
+		"since":     "2025-03-01T11:00:00Z",
+		"sort":      "updated",
+		"direction": "asc",
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		for param, value := range want {
+			if got := q.Get(param); got != value {
+				t.Errorf("%s = %q, want %q", param, got, value)
+			}
+		}
+		json.NewEncoder(w).Encode([]Issue{})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	if _, err := FetchIssues(context.Background(), "o", "r", "", filter, 100); err != nil {
+		t.Fatalf("FetchIssues() error: %v", err)
+	}
+}
+
+func TestSetBaseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/o/r/issues" {
+			t.Errorf("path = %q, want /api/v3/repos/o/r/issues", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode([]Issue{})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	SetBaseURL(srv.URL + "/api/v3/")
+	defer func() { baseURL = old }()
+
+	if _, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 100); err != nil {
+		t.Fatalf("FetchIssues() error: %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrphil/gitissuesum/internal/parallel"
+)
+
+func FetchComments(ctx context.Context, owner, repo, token string, number int) ([]Comment, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments?per_page=100", baseURL, owner, repo, number)
+
+	var all []Comment
+	for url != "" {
+		var comments []Comment
+		nextURL, err := fetchPage(ctx, url, token, &comments)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, comments...)
+		url = nextURL
+	}
+	return all, nil
+}
+
+// FetchAllComments fetches the comments of each numbered issue, running at
+// most concurrency requests at a time. The first error cancels the rest.
+func FetchAllComments(ctx context.Context, owner, repo, token string, numbers []int, concurrency int) (map[int][]Comment, error) {
+	all, err := parallel.Map(ctx, numbers, concurrency, func(ctx context.Context, n int) ([]Comment, error) {
+		comments, err := FetchComments(ctx, owner, repo, token, n)
+		if err != nil {
+			return nil, fmt.Errorf("issue #%d: %w", n, err)
+		}
+		return comments, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int][]Comment, len(numbers))
+	for i, n := range numbers {
+		result[n] = all[i]
+	}
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchComments_Pagination(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/r/issues/7/comments" {
+			t.Errorf("path = %q, want /repos/o/r/issues/7/comments", r.URL.Path)
+		}
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode([]Comment{{ID: 2, Body: "second"}})
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s/repos/o/r/issues/7/comments?page=2>; rel="next"`, r.Host))
+		json.NewEncoder(w).Encode([]Comment{{ID: 1, Body: "first"}})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	got, err := FetchComments(context.Background(), "o", "r", "", 7)
+	if err != nil {
+		t.Fatalf("FetchComments() error: %v", err)
+	}
+	if len(got) != 2 || got[0].Body != "first" || got[1].Body != "second" {
+		t.Errorf("unexpected comments: %v", got)
+	}
+}
+
+func TestFetchAllComments_ConcurrencyLimit(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		parts := strings.Split(r.URL.Path, "/")
+		json.NewEncoder(w).Encode([]Comment{{Body: "on " + parts[len(parts)-2]}})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	numbers := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	got, err := FetchAllComments(context.Background(), "o", "r", "", numbers, 3)
+	if err != nil {
+		t.Fatalf("FetchAllComments() error: %v", err)
+	}
+	if len(got) != len(numbers) {
+		t.Fatalf("got comments for %d issues, want %d", len(got), len(numbers))
+	}
+	if got[5][0].Body != "on 5" {
+		t.Errorf("comments for #5 = %v", got[5])
+	}
+	if peak > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", peak)
+	}
+}
+
+func TestFetchAllComments_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/issues/2/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]Comment{})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	_, err := FetchAllComments(context.Background(), "o", "r", "", []int{1, 2, 3}, 2)
+	if err == nil {
+		t.Fatal("expected error when one issue's comments fail to load")
+	}
+	if !strings.Contains(err.Error(), "issue #2") {
+		t.Errorf("error = %q, want it to name issue #2", err)
+	}
+}
@@ -0,0 +1,174 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/parallel"
+)
+
+// FetchPulls lists up to maxPulls open pull requests, newest first, then
+// fetches the size, reviews, CI status and head commit of each, running at
+// most concurrency pull requests at a time. The first error cancels the
+// rest.
+func FetchPulls(ctx context.Context, owner, repo, token string, maxPulls, concurrency int) ([]PullDetails, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls?state=open&per_page=100", baseURL, owner, repo)
+
+	var numbers []int
+	for url != "" && len(numbers) < maxPulls {
+		var pulls []Pull
+		nextURL, err := fetchPage(ctx, url, token, &pulls)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pulls[:min(len(pulls), maxPulls-len(numbers))] {
+			numbers = append(numbers, p.Number)
+		}
+		url = nextURL
+	}
+
+	return parallel.Map(ctx, numbers, concurrency, func(ctx context.Context, n int) (PullDetails, error) {
+		details, err := fetchPullDetails(ctx, owner, repo, token, n)
+		if err != nil {
+			return details, fmt.Errorf("pull request #%d: %w", n, err)
+		}
+		return details, nil
+	})
+}
+
+func fetchPullDetails(ctx context.Context, owner, repo, token string, number int) (PullDetails, error) {
+	prefix := fmt.Sprintf("%s/repos/%s/%s", baseURL, owner, repo)
+
+	var d PullDetails
+	if _, err := fetchPage(ctx, fmt.Sprintf("%s/pulls/%d", prefix, number), token, &d.Pull); err != nil {
+		return d, err
+	}
+
+	reviews, err := FetchReviews(ctx, owner, repo, token, number)
+	if err != nil {
+		return d, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+	d.ReviewDecision = reviewDecision(reviews)
+
+	if d.Pull.Head.SHA == "" {
+		return d, nil
+	}
+	var status CombinedStatus
+	if _, err := fetchPage(ctx, fmt.Sprintf("%s/commits/%s/status", prefix, d.Pull.Head.SHA), token, &status); err != nil {
+		return d, fmt.Errorf("failed to fetch commit status: %w", err)
+	}
+	runs, err := fetchCheckRuns(ctx, owner, repo, token, d.Pull.Head.SHA)
+	if err != nil {
+		return d, fmt.Errorf("failed to fetch check runs: %w", err)
+	}
+	d.CI = ciStatus(status, runs)
+
+	var commit struct {
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	if _, err := fetchPage(ctx, fmt.Sprintf("%s/commits/%s", prefix, d.Pull.Head.SHA), token, &commit); err != nil {
+		return d, fmt.Errorf("failed to fetch head commit: %w", err)
+	}
+	d.LastCommitAt = commit.Commit.Committer.Date
+
+	return d, nil
+}
+
+func FetchReviews(ctx context.Context, owner, repo, token string, number int) ([]Review, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/pulls/%d/reviews?per_page=100", baseURL, owner, repo, number)
+
+	var all []Review
+	for url != "" {
+		var reviews []Review
+		nextURL, err := fetchPage(ctx, url, token, &reviews)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, reviews...)
+		url = nextURL
+	}
+	return all, nil
+}
+
+// fetchCheckRuns lists every check run of a commit, following the pages.
+func fetchCheckRuns(ctx context.Context, owner, repo, token, sha string) ([]CheckRun, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s/check-runs?per_page=100", baseURL, owner, repo, sha)
+
+	var all []CheckRun
+	for url != "" {
+		var page struct {
+			CheckRuns []CheckRun `json:"check_runs"`
+		}
+		nextURL, err := fetchPage(ctx, url, token, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.CheckRuns...)
+		url = nextURL
+	}
+	return all, nil
+}
+
+// reviewDecision works out where review stands from each reviewer's latest
+// approval, change request or dismissal, as GitHub does. Plain comments
+// don't change a reviewer's verdict.
+func reviewDecision(reviews []Review) string {
+	latest := make(map[string]string)
+	for _, r := range reviews {
+		switch r.State {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+			latest[r.User.Login] = r.State
+		}
+	}
+
+	approved := false
+	for _, state := range latest {
+		switch state {
+		case "CHANGES_REQUESTED":
+			return "changes_requested"
+		case "APPROVED":
+			approved = true
+		}
+	}
+	if approved {
+		return "approved"
+	}
+	return ""
+}
+
+// ciStatus combines the commit statuses and check runs of a commit into one
+// result. Any failure wins over anything still running.
+func ciStatus(status CombinedStatus, runs []CheckRun) string {
+	pending := false
+	if status.TotalCount > 0 {
+		switch status.State {
+		case "failure", "error":
+			return "failing"
+		case "pending":
+			pending = true
+		}
+	}
+	for _, r := range runs {
+		if r.Status != "completed" {
+			pending = true
+			continue
+		}
+		switch r.Conclusion {
+		case "failure", "timed_out", "cancelled", "action_required", "startup_failure":
+			return "failing"
+		}
+	}
+
+	switch {
+	case pending:
+		return "pending"
+	case status.TotalCount > 0 || len(runs) > 0:
+		return "passing"
+	}
+	return ""
+}
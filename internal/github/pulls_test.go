@@ -0,0 +1,135 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchPulls(t *testing.T) {
+	pushed := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	var srvURL string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/pulls":
+			if r.URL.Query().Get("state") != "open" {
+				t.Errorf("state = %q, want open", r.URL.Query().Get("state"))
+			}
+			json.NewEncoder(w).Encode([]Pull{{Number: 5}, {Number: 6}, {Number: 7}})
+		case "/repos/o/r/pulls/5":
+			json.NewEncoder(w).Encode(Pull{
+				Number:             5,
+				Title:              "Add cache",
+				Draft:              true,
+				RequestedReviewers: []User{{Login: "bob"}},
+				Head:               Ref{SHA: "abc"},
+				ChangedFiles:       3,
+			})
+		case "/repos/o/r/pulls/6":
+			json.NewEncoder(w).Encode(Pull{Number: 6, Title: "No head"})
+		case "/repos/o/r/pulls/5/reviews", "/repos/o/r/pulls/6/reviews":
+			json.NewEncoder(w).Encode([]Review{{User: User{Login: "bob"}, State: "APPROVED"}})
+		case "/repos/o/r/commits/abc/status":
+			json.NewEncoder(w).Encode(CombinedStatus{State: "success", TotalCount: 1})
+		case "/repos/o/r/commits/abc/check-runs":
+			if r.URL.Query().Get("page") == "2" {
+				json.NewEncoder(w).Encode(map[string]any{"check_runs": []CheckRun{{Status: "completed", Conclusion: "failure"}}})
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?per_page=100&page=2>; rel="next"`, srvURL, r.URL.Path))
+			json.NewEncoder(w).Encode(map[string]any{"check_runs": []CheckRun{{Status: "in_progress"}}})
+		case "/repos/o/r/commits/abc":
+			json.NewEncoder(w).Encode(map[string]any{"commit": map[string]any{"committer": map[string]any{"date": pushed}}})
+		default:
+			t.Errorf("unexpected request for %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	srvURL = srv.URL
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	got, err := FetchPulls(context.Background(), "o", "r", "", 2, 2)
+	if err != nil {
+		t.Fatalf("FetchPulls() error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d pull requests, want 2", len(got))
+	}
+	first := got[0]
+	if first.Pull.Title != "Add cache" || !first.Pull.Draft || first.Pull.ChangedFiles != 3 || len(first.Pull.RequestedReviewers) != 1 {
+		t.Errorf("pull = %+v", first.Pull)
+	}
+	if first.ReviewDecision != "approved" || first.CI != "failing" || !first.LastCommitAt.Equal(pushed) {
+		t.Errorf("details = %q, %q, %v", first.ReviewDecision, first.CI, first.LastCommitAt)
+	}
+	if got[1].Pull.Number != 6 || got[1].CI != "" {
+		t.Errorf("second = %+v", got[1])
+	}
+}
+
+func TestReviewDecision(t *testing.T) {
+	tests := []struct {
+		name    string
+		reviews []Review
+		want    string
+	}{
+		{"none", nil, ""},
+		{"comments only", []Review{{User: User{Login: "a"}, State: "COMMENTED"}}, ""},
+		{"approved", []Review{{User: User{Login: "a"}, State: "APPROVED"}}, "approved"},
+		{"changes requested wins", []Review{
+			{User: User{Login: "a"}, State: "APPROVED"},
+			{User: User{Login: "b"}, State: "CHANGES_REQUESTED"},
+		}, "changes_requested"},
+		{"later approval replaces change request", []Review{
+			{User: User{Login: "a"}, State: "CHANGES_REQUESTED"},
+			{User: User{Login: "a"}, State: "COMMENTED"},
+			{User: User{Login: "a"}, State: "APPROVED"},
+		}, "approved"},
+		{"dismissed", []Review{
+			{User: User{Login: "a"}, State: "CHANGES_REQUESTED"},
+			{User: User{Login: "a"}, State: "DISMISSED"},
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reviewDecision(tt.reviews); got != tt.want {
+				t.Errorf("reviewDecision() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCIStatus(t *testing.T) {
+	passed := CheckRun{Status: "completed", Conclusion: "success"}
+	failed := CheckRun{Status: "completed", Conclusion: "failure"}
+	running := CheckRun{Status: "queued"}
+
+	tests := []struct {
+		name   string
+		status CombinedStatus
+		runs   []CheckRun
+		want   string
+	}{
+		{"nothing", CombinedStatus{State: "pending"}, nil, ""},
+		{"checks passed", CombinedStatus{}, []CheckRun{passed}, "passing"},
+		{"status failed", CombinedStatus{State: "failure", TotalCount: 1}, []CheckRun{passed}, "failing"},
+		{"failure beats running", CombinedStatus{}, []CheckRun{running, failed}, "failing"},
+		{"still running", CombinedStatus{State: "success", TotalCount: 2}, []CheckRun{passed, running}, "pending"},
+		{"skipped counts as passing", CombinedStatus{}, []CheckRun{{Status: "completed", Conclusion: "skipped"}}, "passing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ciStatus(tt.status, tt.runs); got != tt.want {
+				t.Errorf("ciStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxRetries = 3
+	// maxRateLimitWait bounds how long a single rate-limit pause may last.
+	// The primary limit resets hourly, so this only rejects bogus headers.
+	maxRateLimitWait = time.Hour + time.Minute
+)
+
+var (
+	logOutput io.Writer = os.Stderr
+	verbose   bool
+
+	// secondaryLimitWait is the minimum pause after a secondary rate limit
+	// response that carries no Retry-After header, as GitHub recommends.
+	secondaryLimitWait = time.Minute
+
+	rateMu   sync.Mutex
+	lastRate *RateLimit
+)
+
+// SetVerbose turns on per-request logging, including the remaining quota.
+func SetVerbose(v bool) {
+	verbose = v
+}
+
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+	Resource  string
+}
+
+// CurrentRateLimit returns the quota reported by the most recent response.
+func CurrentRateLimit() (RateLimit, bool) {
+	rateMu.Lock()
+	defer rateMu.Unlock()
+	if lastRate == nil {
+		return RateLimit{}, false
+	}
+	return *lastRate, true
+}
+
+func parseRateLimit(h http.Header) (RateLimit, bool) {
+	remaining, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	rl := RateLimit{Remaining: remaining, Resource: h.Get("X-RateLimit-Resource")}
+	rl.Limit, _ = strconv.Atoi(h.Get("X-RateLimit-Limit"))
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(reset, 0)
+	}
+	return rl, true
+}
+
+func recordRateLimit(resp *http.Response) {
+	rl, ok := parseRateLimit(resp.Header)
+	if verbose {
+		line := fmt.Sprintf("GET %s -> %d", resp.Request.URL, resp.StatusCode)
+		if ok {
+			line += fmt.Sprintf(" (rate limit: %d/%d remaining, resets %s)", rl.Remaining, rl.Limit, rl.Reset.Local().Format(time.TimeOnly))
+		}
+		fmt.Fprintln(logOutput, line)
+	}
+	if ok {
+		rateMu.Lock()
+		lastRate = &rl
+		rateMu.Unlock()
+	}
+}
+
+// rateLimitWait reports whether resp is a rate-limit rejection and, if so,
+// how long to wait before retrying, following GitHub's guidance: honor
+// Retry-After, else wait for X-RateLimit-Reset when the quota is used up,
+// else back off for at least a minute on a secondary limit.
+func rateLimitWait(resp *http.Response, attempt int, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second, true
+	}
+	if rl, ok := parseRateLimit(resp.Header); ok && rl.Remaining == 0 && !rl.Reset.IsZero() {
+		return max(rl.Reset.Sub(now), 0) + time.Second, true
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || isSecondaryLimit(resp) {
+		return secondaryLimitWait << attempt, true
+	}
+	return 0, false
+}
+
+// isSecondaryLimit checks the error message of a 403 response, which is the
+// only way to tell a secondary rate limit from a permissions problem. The
+// body is restored so callers can still read it.
+func isSecondaryLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	msg := strings.ToLower(string(body))
+	return strings.Contains(msg, "secondary rate limit") || strings.Contains(msg, "abuse detection")
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
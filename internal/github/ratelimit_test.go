@@ -0,0 +1,176 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func quietLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	old := logOutput
+	logOutput = &buf
+	t.Cleanup(func() { logOutput = old })
+	return &buf
+}
+
+func limitedResponse(status int, header http.Header, body string) *http.Response {
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-RateLimit-Limit", "5000")
+	h.Set("X-RateLimit-Remaining", "42")
+	h.Set("X-RateLimit-Reset", "1750000000")
+	h.Set("X-RateLimit-Resource", "core")
+
+	rl, ok := parseRateLimit(h)
+	if !ok {
+		t.Fatal("parseRateLimit() ok = false, want true")
+	}
+	if rl.Limit != 5000 || rl.Remaining != 42 || rl.Resource != "core" || !rl.Reset.Equal(time.Unix(1750000000, 0)) {
+		t.Errorf("unexpected rate limit: %+v", rl)
+	}
+
+	if _, ok := parseRateLimit(http.Header{}); ok {
+		t.Error("parseRateLimit() without headers ok = true, want false")
+	}
+}
+
+func TestRateLimitWait_RetryAfter(t *testing.T) {
+	resp := limitedResponse(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"30"}}, "")
+	wait, limited := rateLimitWait(resp, 0, time.Now())
+	if !limited || wait != 30*time.Second {
+		t.Errorf("rateLimitWait() = %v, %v; want 30s, true", wait, limited)
+	}
+}
+
+func TestRateLimitWait_PrimaryReset(t *testing.T) {
+	now := time.Unix(1000, 0)
+	h := http.Header{}
+	h.Set("X-RateLimit-Remaining", "0")
+	h.Set("X-RateLimit-Reset", "1120")
+
+	wait, limited := rateLimitWait(limitedResponse(http.StatusForbidden, h, ""), 0, now)
+	if !limited || wait != 121*time.Second {
+		t.Errorf("rateLimitWait() = %v, %v; want 2m1s, true", wait, limited)
+	}
+}
+
+func TestRateLimitWait_SecondaryLimit(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-RateLimit-Remaining", "4000")
+	resp := limitedResponse(http.StatusForbidden, h, `{"message":"You have exceeded a secondary rate limit."}`)
+
+	wait, limited := rateLimitWait(resp, 1, time.Now())
+	if !limited || wait != 2*secondaryLimitWait {
+		t.Errorf("rateLimitWait() = %v, %v; want %v, true", wait, limited, 2*secondaryLimitWait)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "secondary rate limit") {
+		t.Error("response body should still be readable after inspection")
+	}
+}
+
+func TestRateLimitWait_PermissionDenied(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-RateLimit-Remaining", "4000")
+	resp := limitedResponse(http.StatusForbidden, h, `{"message":"Resource not accessible by integration"}`)
+
+	if _, limited := rateLimitWait(resp, 0, time.Now()); limited {
+		t.Error("plain 403 should not be treated as a rate limit")
+	}
+}
+
+func TestFetchIssues_RetriesAfterRateLimit(t *testing.T) {
+	quietLogs(t)
+	requests := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode([]Issue{{Number: 1}})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	got, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 100)
+	if err != nil {
+		t.Fatalf("FetchIssues() error: %v", err)
+	}
+	if len(got) != 1 || requests != 2 {
+		t.Errorf("got %d issues after %d requests, want 1 after 2", len(got), requests)
+	}
+}
+
+func TestFetchIssues_RateLimitWaitCanceled(t *testing.T) {
+	quietLogs(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(30*time.Minute).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := FetchIssues(ctx, "o", "r", "", IssueFilter{}, 100)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("FetchIssues() error = %v, want context deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancellation took %s, want it to interrupt the wait", elapsed)
+	}
+}
+
+func TestFetchIssues_VerboseReportsQuota(t *testing.T) {
+	logs := quietLogs(t)
+	SetVerbose(true)
+	defer SetVerbose(false)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("X-RateLimit-Reset", "1750000000")
+		json.NewEncoder(w).Encode([]Issue{})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	if _, err := FetchIssues(context.Background(), "o", "r", "", IssueFilter{}, 100); err != nil {
+		t.Fatalf("FetchIssues() error: %v", err)
+	}
+	if !strings.Contains(logs.String(), "4999/5000 remaining") {
+		t.Errorf("verbose output = %q, want remaining quota", logs.String())
+	}
+	if rl, ok := CurrentRateLimit(); !ok || rl.Remaining != 4999 {
+		t.Errorf("CurrentRateLimit() = %+v, %v", rl, ok)
+	}
+}
@@ -0,0 +1,37 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+type Repository struct {
+	Name      string `json:"name"`
+	FullName  string `json:"full_name"`
+	Owner     User   `json:"owner"`
+	Archived  bool   `json:"archived"`
+	HasIssues bool   `json:"has_issues"`
+}
+
+// FetchOrgRepos lists an organization's repositories that can have issues,
+// leaving out archived ones and those with issues turned off.
+func FetchOrgRepos(ctx context.Context, org, token string) ([]Repository, error) {
+	url := fmt.Sprintf("%s/orgs/%s/repos?type=all&sort=full_name&per_page=100", baseURL, url.PathEscape(org))
+
+	var all []Repository
+	for url != "" {
+		var repos []Repository
+		nextURL, err := fetchPage(ctx, url, token, &repos)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range repos {
+			if !r.Archived && r.HasIssues {
+				all = append(all, r)
+			}
+		}
+		url = nextURL
+	}
+	return all, nil
+}
@@ -0,0 +1,41 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchOrgRepos(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/acme/repos" {
+			t.Errorf("path = %q, want /orgs/acme/repos", r.URL.Path)
+		}
+		if r.URL.Query().Get("page") == "2" {
+			json.NewEncoder(w).Encode([]Repository{{Name: "web", HasIssues: true}})
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s/orgs/acme/repos?page=2>; rel="next"`, r.Host))
+		json.NewEncoder(w).Encode([]Repository{
+			{Name: "api", HasIssues: true},
+			{Name: "old", HasIssues: true, Archived: true},
+			{Name: "wiki", HasIssues: false},
+		})
+	}))
+	defer srv.Close()
+
+	old := baseURL
+	baseURL = srv.URL
+	defer func() { baseURL = old }()
+
+	got, err := FetchOrgRepos(context.Background(), "acme", "")
+	if err != nil {
+		t.Fatalf("FetchOrgRepos() error: %v", err)
+	}
+	if len(got) != 2 || got[0].Name != "api" || got[1].Name != "web" {
+		t.Errorf("unexpected repos: %v", got)
+	}
+}
@@ -0,0 +1,153 @@
+package github
+
+import (
+	"context"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+const (
+	commentConcurrency = 8
+	pullConcurrency    = 8
+)
+
+// Source reads a GitHub repository's issues for summarize.Run.
+type Source struct {
+	owner, repo, token string
+}
+
+func NewSource(owner, repo, token string) *Source {
+	return &Source{owner: owner, repo: repo, token: token}
+}
+
+func (s *Source) Name() string {
+	return "GitHub"
+}
+
+func (s *Source) Repo() string {
+	return s.owner + "/" + s.repo
+}
+
+func (s *Source) FetchIssues(ctx context.Context, filter summarize.Filter, maxIssues int) ([]summarize.Issue, error) {
+	issues, err := FetchIssues(ctx, s.owner, s.repo, s.token, issueFilter(filter), maxIssues)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]summarize.Issue, len(issues))
+	for i, issue := range issues {
+		result[i] = issue.neutral()
+	}
+	return result, nil
+}
+
+func (s *Source) FetchComments(ctx context.Context, numbers []int) (map[int][]summarize.Comment, error) {
+	all, err := FetchAllComments(ctx, s.owner, s.repo, s.token, numbers, commentConcurrency)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int][]summarize.Comment, len(all))
+	for n, comments := range all {
+		converted := make([]summarize.Comment, len(comments))
+		for i, c := range comments {
+			converted[i] = c.neutral()
+		}
+		result[n] = converted
+	}
+	return result, nil
+}
+
+func (s *Source) FetchPulls(ctx context.Context, maxPulls int) ([]summarize.Pull, error) {
+	pulls, err := FetchPulls(ctx, s.owner, s.repo, s.token, maxPulls, pullConcurrency)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]summarize.Pull, len(pulls))
+	for i, p := range pulls {
+		result[i] = p.neutral()
+	}
+	return result, nil
+}
+
+func issueFilter(f summarize.Filter) IssueFilter {
+	return IssueFilter{
+		State:     f.State,
+		Labels:    f.Labels,
+		Assignee:  f.Assignee,
+		Creator:   f.Author,
+		Milestone: f.Milestone,
+		Since:     f.Since,
+		Sort:      f.Sort,
+		Direction: f.Direction,
+	}
+}
+
+func (issue Issue) neutral() summarize.Issue {
+	labels := make([]string, len(issue.Labels))
+	for i, l := range issue.Labels {
+		labels[i] = l.Name
+	}
+	return summarize.Issue{
+		Number:    issue.Number,
+		Title:     issue.Title,
+		Body:      issue.Body,
+		State:     issue.State,
+		Author:    issue.User.Login,
+		Labels:    labels,
+		Comments:  issue.Comments,
+		CreatedAt: issue.CreatedAt,
+		UpdatedAt: issue.UpdatedAt,
+		URL:       issue.HTMLURL,
+	}
+}
+
+func (d PullDetails) neutral() summarize.Pull {
+	p := d.Pull
+	labels := make([]string, len(p.Labels))
+	for i, l := range p.Labels {
+		labels[i] = l.Name
+	}
+	var reviewers []string
+	for _, u := range p.RequestedReviewers {
+		reviewers = append(reviewers, u.Login)
+	}
+	for _, t := range p.RequestedTeams {
+		reviewers = append(reviewers, "team "+t.Slug)
+	}
+	return summarize.Pull{
+		Number:             p.Number,
+		Title:              p.Title,
+		Body:               p.Body,
+		Author:             p.User.Login,
+		Draft:              p.Draft,
+		Labels:             labels,
+		ReviewDecision:     d.ReviewDecision,
+		RequestedReviewers: reviewers,
+		ChangedFiles:       p.ChangedFiles,
+		Additions:          p.Additions,
+		Deletions:          p.Deletions,
+		CI:                 d.CI,
+		Comments:           p.Comments + p.ReviewComments,
+		CreatedAt:          p.CreatedAt,
+		LastPushAt:         d.LastCommitAt,
+		URL:                p.HTMLURL,
+	}
+}
+
+func (c Comment) neutral() summarize.Comment {
+	return summarize.Comment{
+		Author:     c.User.Login,
+		Body:       c.Body,
+		Maintainer: isMaintainer(c),
+		CreatedAt:  c.CreatedAt,
+	}
+}
+
+// isMaintainer reports whether a comment comes from someone with a formal
+// role in the repository.
+func isMaintainer(c Comment) bool {
+	switch c.AuthorAssociation {
+	case "OWNER", "MEMBER", "COLLABORATOR":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+func TestIssue_Neutral(t *testing.T) {
+	got := Issue{Number: 3, User: User{Login: "alice"}, Labels: []Label{{Name: "bug"}}}.neutral()
+	if got.Number != 3 || got.Author != "alice" || len(got.Labels) != 1 || got.Labels[0] != "bug" {
+		t.Errorf("neutral() = %+v", got)
+	}
+}
+
+func TestComment_NeutralMaintainer(t *testing.T) {
+	for association, want := range map[string]bool{"OWNER": true, "MEMBER": true, "COLLABORATOR": true, "CONTRIBUTOR": false, "NONE": false} {
+		if got := (Comment{AuthorAssociation: association}).neutral().Maintainer; got != want {
+			t.Errorf("Maintainer for %s = %v, want %v", association, got, want)
+		}
+	}
+}
+
+func TestIssueFilter_Author(t *testing.T) {
+	if got := issueFilter(summarize.Filter{Author: "bob"}); got.Creator != "bob" {
+		t.Errorf("Creator = %q, want bob", got.Creator)
+	}
+}
@@ -3,14 +3,17 @@ package github
 import "time"
 
 type Issue struct {
-	Number      int           `json:"number"`
-	Title       string        `json:"title"`
-	Body        string        `json:"body"`
-	User        User          `json:"user"`
-	Labels      []Label       `json:"labels"`
-	Comments    int           `json:"comments"`
-	CreatedAt   time.Time     `json:"created_at"`
-	PullRequest *PullRequest  `json:"pull_request,omitempty"`
+	Number      int          `json:"number"`
+	Title       string       `json:"title"`
+	Body        string       `json:"body"`
+	State       string       `json:"state"`
+	User        User         `json:"user"`
+	Labels      []Label      `json:"labels"`
+	Comments    int          `json:"comments"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+	HTMLURL     string       `json:"html_url"`
+	PullRequest *PullRequest `json:"pull_request,omitempty"`
 }
 
 type User struct {
@@ -21,6 +24,92 @@ type Label struct {
 	Name string `json:"name"`
 }
 
+type Comment struct {
+	ID                int64     `json:"id"`
+	User              User      `json:"user"`
+	Body              string    `json:"body"`
+	AuthorAssociation string    `json:"author_association"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
 type PullRequest struct {
 	URL string `json:"url"`
 }
+
+// IssueFilter holds the query parameters accepted by the list repository
+// issues endpoint. Zero values are left out of the request, except State,
+// which defaults to "open".
+type IssueFilter struct {
+	State     string
+	Labels    []string
+	Assignee  string
+	Creator   string
+	Milestone string
+	Since     time.Time
+	Sort      string
+	Direction string
+}
+
+type Team struct {
+	Slug string `json:"slug"`
+}
+
+// Pull is a pull request as returned by the get pull request endpoint. The
+// list endpoint leaves out the size fields, which FetchPulls fills in.
+type Pull struct {
+	Number             int       `json:"number"`
+	Title              string    `json:"title"`
+	Body               string    `json:"body"`
+	State              string    `json:"state"`
+	User               User      `json:"user"`
+	Draft              bool      `json:"draft"`
+	Labels             []Label   `json:"labels"`
+	RequestedReviewers []User    `json:"requested_reviewers"`
+	RequestedTeams     []Team    `json:"requested_teams"`
+	Head               Ref       `json:"head"`
+	ChangedFiles       int       `json:"changed_files"`
+	Additions          int       `json:"additions"`
+	Deletions          int       `json:"deletions"`
+	Comments           int       `json:"comments"`
+	ReviewComments     int       `json:"review_comments"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	HTMLURL            string    `json:"html_url"`
+}
+
+type Ref struct {
+	SHA string `json:"sha"`
+}
+
+type Review struct {
+	User        User      `json:"user"`
+	State       string    `json:"state"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// CombinedStatus is the combined commit status of a ref, from the older
+// statuses API.
+type CombinedStatus struct {
+	State      string `json:"state"`
+	TotalCount int    `json:"total_count"`
+}
+
+type CheckRun struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+}
+
+// PullDetails is what FetchPulls gathers about a pull request besides the
+// pull request itself.
+type PullDetails struct {
+	Pull Pull
+	// ReviewDecision takes the values documented on summarize.Pull.
+	ReviewDecision string
+	// CI is "passing", "failing", "pending", or empty when the head commit
+	// has no statuses or check runs.
+	CI string
+	// LastCommitAt is when the head commit was committed, which tracks the
+	// last push closely since rebases and amends rewrite it.
+	LastCommitAt time.Time
+}
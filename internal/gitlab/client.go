@@ -0,0 +1,218 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/parallel"
+)
+
+const maxRetries = 3
+
+var (
+	linkNextRe = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+	baseURL    = "https://gitlab.com/api/v4"
+	httpClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// SetBaseURL points the client at a self-managed instance's
+// https://host/api/v4.
+func SetBaseURL(u string) {
+	baseURL = strings.TrimSuffix(u, "/")
+}
+
+// FetchIssues lists a project's issues, where project is its full path such
+// as group/subgroup/name. It asks for keyset pagination and follows the
+// Link header, which offset pagination also provides as a fallback.
+func FetchIssues(ctx context.Context, project, token string, filter IssueFilter, maxIssues int) ([]Issue, error) {
+	url := fmt.Sprintf("%s/projects/%s/issues?%s", baseURL, url.PathEscape(project), filter.query().Encode())
+
+	var all []Issue
+	for url != "" && len(all) < maxIssues {
+		var issues []Issue
+		nextURL, err := fetchPage(ctx, url, token, &issues)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			all = append(all, issue)
+			if len(all) >= maxIssues {
+				break
+			}
+		}
+		url = nextURL
+	}
+	return all, nil
+}
+
+func (f IssueFilter) query() url.Values {
+	q := url.Values{}
+	q.Set("pagination", "keyset")
+	q.Set("per_page", "100")
+	q.Set("order_by", "created_at")
+	q.Set("sort", "desc")
+	if f.State != "" {
+		q.Set("state", f.State)
+	}
+	if len(f.Labels) > 0 {
+		q.Set("labels", strings.Join(f.Labels, ","))
+	}
+	if f.AssigneeUsername != "" {
+		q.Set("assignee_username", f.AssigneeUsername)
+	}
+	if f.AssigneeID != "" {
+		q.Set("assignee_id", f.AssigneeID)
+	}
+	if f.AuthorUsername != "" {
+		q.Set("author_username", f.AuthorUsername)
+	}
+	if f.Milestone != "" {
+		q.Set("milestone", f.Milestone)
+	}
+	if !f.UpdatedAfter.IsZero() {
+		q.Set("updated_after", f.UpdatedAfter.UTC().Format(time.RFC3339))
+	}
+	if f.OrderBy != "" {
+		q.Set("order_by", f.OrderBy)
+	}
+	if f.Sort != "" {
+		q.Set("sort", f.Sort)
+	}
+	return q
+}
+
+// FetchNotes returns the user comments on an issue, oldest first. System
+// notes such as "changed the label" are left out.
+func FetchNotes(ctx context.Context, project, token string, iid int) ([]Note, error) {
+	url := fmt.Sprintf("%s/projects/%s/issues/%d/notes?sort=asc&order_by=created_at&per_page=100", baseURL, url.PathEscape(project), iid)
+
+	var all []Note
+	for url != "" {
+		var notes []Note
+		nextURL, err := fetchPage(ctx, url, token, &notes)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notes {
+			if !n.System {
+				all = append(all, n)
+			}
+		}
+		url = nextURL
+	}
+	return all, nil
+}
+
+// FetchAllNotes fetches the notes of each issue, running at most
+// concurrency requests at a time. The first error cancels the rest.
+func FetchAllNotes(ctx context.Context, project, token string, iids []int, concurrency int) (map[int][]Note, error) {
+	all, err := parallel.Map(ctx, iids, concurrency, func(ctx context.Context, iid int) ([]Note, error) {
+		notes, err := FetchNotes(ctx, project, token, iid)
+		if err != nil {
+			return nil, fmt.Errorf("issue #%d: %w", iid, err)
+		}
+		return notes, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int][]Note, len(iids))
+	for i, iid := range iids {
+		result[iid] = all[i]
+	}
+	return result, nil
+}
+
+func fetchPage(ctx context.Context, url, token string, v any) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "gitissuesum")
+	if token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+
+	resp, err := doWithRetry(req)
+	if err != nil {
+		return "", fmt.Errorf("GitLab API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitLab API returned status %d for %s", resp.StatusCode, url)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("failed to decode GitLab response: %w", err)
+	}
+
+	return parseNextLink(resp.Header.Get("Link")), nil
+}
+
+// doWithRetry retries rate-limited and unavailable responses, waiting as
+// long as Retry-After asks when GitLab sends it.
+func doWithRetry(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil || attempt == maxRetries {
+				return nil, err
+			}
+			if err := sleepContext(ctx, time.Second<<attempt); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			if attempt == maxRetries {
+				return resp, nil
+			}
+			wait := time.Second << attempt
+			if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				wait = time.Duration(max(secs, 0)) * time.Second
+			}
+			resp.Body.Close()
+			if err := sleepContext(ctx, wait); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		return resp, nil
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func parseNextLink(header string) string {
+	if header == "" {
+		return ""
+	}
+	for _, part := range strings.Split(header, ",") {
+		if matches := linkNextRe.FindStringSubmatch(part); len(matches) == 2 {
+			return matches[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func withServer(t *testing.T, h http.HandlerFunc) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	old := baseURL
+	baseURL = srv.URL
+	t.Cleanup(func() { baseURL = old })
+}
+
+func TestFetchIssues_KeysetPagination(t *testing.T) {
+	withServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.EscapedPath(); got != "/projects/g%2Fsub%2Fp/issues" {
+			t.Errorf("path = %q, want the project path escaped as one segment", got)
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "tok" {
+			t.Errorf("PRIVATE-TOKEN = %q, want tok", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		q := r.URL.Query()
+		if q.Get("pagination") != "keyset" {
+			t.Errorf("pagination = %q, want keyset", q.Get("pagination"))
+		}
+		if q.Get("cursor") == "abc" {
+			json.NewEncoder(w).Encode([]Issue{{IID: 2, Title: "second"}})
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s/projects/g%%2Fsub%%2Fp/issues?pagination=keyset&cursor=abc>; rel="next"`, r.Host))
+		json.NewEncoder(w).Encode([]Issue{{IID: 1, Title: "first"}})
+	})
+
+	got, err := FetchIssues(context.Background(), "g/sub/p", "tok", IssueFilter{}, 100)
+	if err != nil {
+		t.Fatalf("FetchIssues() error: %v", err)
+	}
+	if len(got) != 2 || got[0].IID != 1 || got[1].IID != 2 {
+		t.Errorf("unexpected issues: %v", got)
+	}
+}
+
+func TestFetchIssues_MaxIssues(t *testing.T) {
+	withServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]Issue{{IID: 1}, {IID: 2}, {IID: 3}})
+	})
+
+	got, err := FetchIssues(context.Background(), "g/p", "", IssueFilter{}, 2)
+	if err != nil {
+		t.Fatalf("FetchIssues() error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("got %d issues, want 2", len(got))
+	}
+}
+
+func TestIssueFilter_Query(t *testing.T) {
+	f := IssueFilter{
+		State:            "opened",
+		Labels:           []string{"bug", "ui"},
+		AssigneeUsername: "alice",
+		Milestone:        "v1.0",
+		UpdatedAfter:     time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		OrderBy:          "updated_at",
+		Sort:             "asc",
+	}
+	q := f.query()
+
+	want := map[string]string{
+		"state":             "opened",
+		"labels":            "bug,ui",
+		"assignee_username": "alice",
+		"milestone":         "v1.0",
+		"updated_after":     "2025-06-01T00:00:00Z",
+		"order_by":          "updated_at",
+		"sort":              "asc",
+	}
+	for k, v := range want {
+		if got := q.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+func TestFetchNotes_SkipsSystemNotes(t *testing.T) {
+	withServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/projects/g/p/issues/7/notes" {
+			t.Errorf("path = %q, want /projects/g/p/issues/7/notes", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode([]Note{
+			{ID: 1, Body: "added ~bug label", System: true},
+			{ID: 2, Body: "Same here"},
+		})
+	})
+
+	got, err := FetchNotes(context.Background(), "g/p", "", 7)
+	if err != nil {
+		t.Fatalf("FetchNotes() error: %v", err)
+	}
+	if len(got) != 1 || got[0].Body != "Same here" {
+		t.Errorf("unexpected notes: %v", got)
+	}
+}
+
+func TestFetchAllNotes_Error(t *testing.T) {
+	withServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/projects/g/p/issues/2/notes" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]Note{})
+	})
+
+	_, err := FetchAllNotes(context.Background(), "g/p", "", []int{1, 2, 3}, 2)
+	if err == nil {
+		t.Fatal("expected error when one issue's notes fail")
+	}
+}
+
+func TestFetchIssues_RetryAfter(t *testing.T) {
+	requests := 0
+	withServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode([]Issue{{IID: 1}})
+	})
+
+	got, err := FetchIssues(context.Background(), "g/p", "", IssueFilter{}, 100)
+	if err != nil {
+		t.Fatalf("FetchIssues() error: %v", err)
+	}
+	if requests != 2 || len(got) != 1 {
+		t.Errorf("requests = %d, issues = %d; want 2 and 1", requests, len(got))
+	}
+}
@@ -0,0 +1,130 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+const noteConcurrency = 8
+
+// Source reads a GitLab project's issues for summarize.Run.
+type Source struct {
+	project, token string
+}
+
+// NewSource returns a source for the project at its full path, such as
+// group/subgroup/name.
+func NewSource(project, token string) *Source {
+	return &Source{project: project, token: token}
+}
+
+func (s *Source) Name() string {
+	return "GitLab"
+}
+
+func (s *Source) Repo() string {
+	return s.project
+}
+
+func (s *Source) FetchIssues(ctx context.Context, filter summarize.Filter, maxIssues int) ([]summarize.Issue, error) {
+	f, err := issueFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := FetchIssues(ctx, s.project, s.token, f, maxIssues)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]summarize.Issue, len(issues))
+	for i, issue := range issues {
+		result[i] = issue.neutral()
+	}
+	return result, nil
+}
+
+func (s *Source) FetchComments(ctx context.Context, numbers []int) (map[int][]summarize.Comment, error) {
+	all, err := FetchAllNotes(ctx, s.project, s.token, numbers, noteConcurrency)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int][]summarize.Comment, len(all))
+	for iid, notes := range all {
+		converted := make([]summarize.Comment, len(notes))
+		for i, n := range notes {
+			converted[i] = summarize.Comment{Author: n.Author.Username, Body: n.Body, CreatedAt: n.CreatedAt}
+		}
+		result[iid] = converted
+	}
+	return result, nil
+}
+
+// issueFilter translates the GitHub-style filter values into GitLab's:
+// "none" and "*" become None and Any, states are renamed, and sort keys
+// gain their _at suffix. GitLab cannot order issues by comment count.
+func issueFilter(f summarize.Filter) (IssueFilter, error) {
+	gf := IssueFilter{
+		Labels:         f.Labels,
+		AuthorUsername: f.Author,
+		UpdatedAfter:   f.Since,
+		Sort:           f.Direction,
+	}
+
+	switch f.State {
+	case "", "open":
+		gf.State = "opened"
+	case "closed":
+		gf.State = "closed"
+	case "all":
+	default:
+		return IssueFilter{}, fmt.Errorf("unsupported issue state %q", f.State)
+	}
+
+	switch f.Assignee {
+	case "":
+	case "none":
+		gf.AssigneeID = "None"
+	case "*":
+		gf.AssigneeID = "Any"
+	default:
+		gf.AssigneeUsername = f.Assignee
+	}
+
+	switch f.Milestone {
+	case "none":
+		gf.Milestone = "None"
+	case "*":
+		gf.Milestone = "Any"
+	default:
+		gf.Milestone = f.Milestone
+	}
+
+	switch f.Sort {
+	case "":
+	case "created", "updated":
+		gf.OrderBy = f.Sort + "_at"
+	default:
+		return IssueFilter{}, fmt.Errorf("GitLab cannot sort issues by %q", f.Sort)
+	}
+	return gf, nil
+}
+
+func (issue Issue) neutral() summarize.Issue {
+	state := issue.State
+	if state == "opened" {
+		state = "open"
+	}
+	return summarize.Issue{
+		Number:    issue.IID,
+		Title:     issue.Title,
+		Body:      issue.Description,
+		State:     state,
+		Author:    issue.Author.Username,
+		Labels:    issue.Labels,
+		Comments:  issue.UserNotesCount,
+		CreatedAt: issue.CreatedAt,
+		UpdatedAt: issue.UpdatedAt,
+		URL:       issue.WebURL,
+	}
+}
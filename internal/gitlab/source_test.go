@@ -0,0 +1,49 @@
+package gitlab
+
+import (
+	"testing"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+func TestIssueFilter_Translates(t *testing.T) {
+	got, err := issueFilter(summarize.Filter{Assignee: "none", Milestone: "*", Sort: "updated", Direction: "asc"})
+	if err != nil {
+		t.Fatalf("issueFilter() error: %v", err)
+	}
+	if got.State != "opened" {
+		t.Errorf("State = %q, want opened", got.State)
+	}
+	if got.AssigneeID != "None" || got.AssigneeUsername != "" {
+		t.Errorf("assignee = %q/%q, want AssigneeID None", got.AssigneeID, got.AssigneeUsername)
+	}
+	if got.Milestone != "Any" {
+		t.Errorf("Milestone = %q, want Any", got.Milestone)
+	}
+	if got.OrderBy != "updated_at" || got.Sort != "asc" {
+		t.Errorf("order = %q %q, want updated_at asc", got.OrderBy, got.Sort)
+	}
+}
+
+func TestIssueFilter_AllStates(t *testing.T) {
+	got, err := issueFilter(summarize.Filter{State: "all"})
+	if err != nil {
+		t.Fatalf("issueFilter() error: %v", err)
+	}
+	if got.State != "" {
+		t.Errorf("State = %q, want it left out", got.State)
+	}
+}
+
+func TestIssueFilter_SortByComments(t *testing.T) {
+	if _, err := issueFilter(summarize.Filter{Sort: "comments"}); err == nil {
+		t.Fatal("expected error for sorting by comments")
+	}
+}
+
+func TestIssue_Neutral(t *testing.T) {
+	got := Issue{IID: 4, State: "opened", Author: User{Username: "bob"}, Description: "d", UserNotesCount: 2}.neutral()
+	if got.Number != 4 || got.State != "open" || got.Author != "bob" || got.Body != "d" || got.Comments != 2 {
+		t.Errorf("neutral() = %+v", got)
+	}
+}
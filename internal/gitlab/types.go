@@ -0,0 +1,43 @@
+package gitlab
+
+import "time"
+
+type Issue struct {
+	IID            int       `json:"iid"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	State          string    `json:"state"`
+	Author         User      `json:"author"`
+	Labels         []string  `json:"labels"`
+	UserNotesCount int       `json:"user_notes_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	WebURL         string    `json:"web_url"`
+}
+
+type User struct {
+	Username string `json:"username"`
+}
+
+type Note struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	Author    User      `json:"author"`
+	System    bool      `json:"system"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IssueFilter holds the query parameters accepted by the list project
+// issues endpoint. Zero values are left out of the request.
+type IssueFilter struct {
+	State            string
+	Labels           []string
+	AssigneeUsername string
+	// AssigneeID is "None" or "Any", which have no username equivalent.
+	AssigneeID     string
+	AuthorUsername string
+	Milestone      string
+	UpdatedAfter   time.Time
+	OrderBy        string
+	Sort           string
+}
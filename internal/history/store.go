@@ -0,0 +1,133 @@
+// Package history keeps snapshots of past runs on disk, so a run can report
+// what changed since the previous one.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+// maxSnapshots is how many snapshots are kept per repository; older ones
+// are removed when a new one is saved.
+const maxSnapshots = 30
+
+const fileTimeFormat = "20060102T150405.000Z"
+
+// Store holds snapshots in one directory per repository, one file per run,
+// named by the time it was taken so they sort oldest first.
+type Store struct {
+	Dir string
+}
+
+type Entry struct {
+	Path    string
+	TakenAt time.Time
+}
+
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// repoDir maps a repository key, such as github.com/owner/repo, to a single
+// directory name, so keys can never reach outside the store.
+func (s *Store) repoDir(key string) string {
+	return filepath.Join(s.Dir, url.PathEscape(key))
+}
+
+// Save writes snap under key and prunes the oldest snapshots beyond
+// maxSnapshots.
+func (s *Store) Save(key string, snap *summarize.Snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	dir := s.repoDir(key)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	name := snap.TakenAt.UTC().Format(fileTimeFormat) + ".json"
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, name)); err != nil {
+		return err
+	}
+
+	entries, err := s.List(key)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries[:max(len(entries)-maxSnapshots, 0)] {
+		os.Remove(e.Path)
+	}
+	return nil
+}
+
+// List returns the snapshots saved under key, oldest first.
+func (s *Store) List(key string) ([]Entry, error) {
+	dirEntries, err := os.ReadDir(s.repoDir(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, d := range dirEntries {
+		name, ok := strings.CutSuffix(d.Name(), ".json")
+		if !ok || !d.Type().IsRegular() {
+			continue
+		}
+		t, err := time.Parse(fileTimeFormat, name)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{Path: filepath.Join(s.repoDir(key), d.Name()), TakenAt: t})
+	}
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return a.TakenAt.Compare(b.TakenAt)
+	})
+	return entries, nil
+}
+
+// Latest returns the most recent snapshot under key, or nil if there is
+// none.
+func (s *Store) Latest(key string) (*summarize.Snapshot, error) {
+	entries, err := s.List(key)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+	return Load(entries[len(entries)-1].Path)
+}
+
+func Load(path string) (*summarize.Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap summarize.Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
@@ -0,0 +1,69 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+func TestStore_SaveLatest(t *testing.T) {
+	s := NewStore(t.TempDir())
+	base := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		snap := &summarize.Snapshot{Repository: "o/r", TakenAt: base.Add(time.Duration(i) * time.Hour), Summary: string(rune('a' + i))}
+		if err := s.Save("github.com/o/r", snap); err != nil {
+			t.Fatalf("Save() error: %v", err)
+		}
+	}
+
+	got, err := s.Latest("github.com/o/r")
+	if err != nil {
+		t.Fatalf("Latest() error: %v", err)
+	}
+	if got == nil || got.Summary != "c" || !got.TakenAt.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("Latest() = %+v, want the third snapshot", got)
+	}
+}
+
+func TestStore_LatestMissing(t *testing.T) {
+	s := NewStore(filepath.Join(t.TempDir(), "missing"))
+	if got, err := s.Latest("github.com/o/r"); got != nil || err != nil {
+		t.Errorf("Latest() = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestStore_KeepsReposApart(t *testing.T) {
+	s := NewStore(t.TempDir())
+	if err := s.Save("github.com/o/r", &summarize.Snapshot{TakenAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Latest("ghe.example.com/o/r"); got != nil {
+		t.Error("snapshot from another host should not be returned")
+	}
+	if dir := s.repoDir("../../etc"); filepath.Dir(dir) != s.Dir {
+		t.Errorf("repoDir escaped the store: %s", dir)
+	}
+}
+
+func TestStore_Prunes(t *testing.T) {
+	s := NewStore(t.TempDir())
+	base := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := range maxSnapshots + 2 {
+		if err := s.Save("k", &summarize.Snapshot{TakenAt: base.Add(time.Duration(i) * time.Minute)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := s.List("k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != maxSnapshots {
+		t.Fatalf("got %d snapshots, want %d", len(entries), maxSnapshots)
+	}
+	if !entries[0].TakenAt.Equal(base.Add(2 * time.Minute)) {
+		t.Errorf("oldest kept = %v, want the two oldest pruned", entries[0].TakenAt)
+	}
+}
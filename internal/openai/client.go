@@ -0,0 +1,172 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+var (
+	httpClient = &http.Client{Timeout: 120 * time.Second}
+	// streamClient has no overall timeout; ctx bounds streamed responses.
+	streamClient = &http.Client{}
+)
+
+// Client talks to servers implementing the OpenAI chat completions API. It
+// takes and returns the claude package's types so it can stand in for the
+// Anthropic client.
+type Client struct {
+	// BaseURL is the API root that /chat/completions is appended to,
+	// e.g. http://localhost:8000/v1.
+	BaseURL string
+	// APIKey is optional, since local servers often need none.
+	APIKey string
+}
+
+func NewClient(baseURL, apiKey string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), APIKey: apiKey}
+}
+
+func (c *Client) Send(ctx context.Context, req claude.Request) (*claude.Response, error) {
+	httpResp, err := c.post(ctx, httpClient, toChatRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	var result ChatResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode chat completion: %w", err)
+	}
+	if len(result.Choices) == 0 {
+		return nil, fmt.Errorf("empty response from chat completions API")
+	}
+
+	resp := textResponse(result.Choices[0].Message.Content)
+	resp.StopReason = toStopReason(result.Choices[0].FinishReason)
+	resp.Usage = toUsage(result.Usage)
+	return resp, nil
+}
+
+// toChatRequest translates a Messages API request. The system prompt
+// becomes a leading system message and the metadata's user ID the user
+// field.
+func toChatRequest(req claude.Request) ChatRequest {
+	out := ChatRequest{
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Stop:        req.StopSequences,
+	}
+	if req.Metadata != nil {
+		out.User = req.Metadata.UserID
+	}
+	if req.System != "" {
+		out.Messages = append(out.Messages, ChatMessage{Role: "system", Content: req.System})
+	}
+	for _, m := range req.Messages {
+		out.Messages = append(out.Messages, ChatMessage{Role: m.Role, Content: m.Text()})
+	}
+	return out
+}
+
+func textResponse(text string) *claude.Response {
+	return &claude.Response{Content: []claude.ContentBlock{{Type: "text", Text: text}}}
+}
+
+// toStopReason converts a finish reason to the Messages API's stop reason,
+// so that replies cut off at the token limit are recognised.
+func toStopReason(finishReason string) string {
+	switch finishReason {
+	case "length":
+		return claude.StopMaxTokens
+	case "stop":
+		return "end_turn"
+	}
+	return finishReason
+}
+
+// toUsage converts chat completion usage to the Messages API form, where
+// input tokens exclude those read from the prompt cache.
+func toUsage(u *Usage) claude.Usage {
+	if u == nil {
+		return claude.Usage{}
+	}
+	usage := claude.Usage{InputTokens: u.PromptTokens, OutputTokens: u.CompletionTokens}
+	if u.PromptTokensDetails != nil {
+		usage.CacheReadInputTokens = u.PromptTokensDetails.CachedTokens
+		usage.InputTokens -= usage.CacheReadInputTokens
+	}
+	return usage
+}
+
+func (c *Client) post(ctx context.Context, client *http.Client, chatReq ChatRequest) (*http.Response, error) {
+	body, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+	if chatReq.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := doWithRetry(client, req, body)
+	if err != nil {
+		return nil, fmt.Errorf("chat completions request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg := fmt.Sprintf("chat completions API returned status %d", resp.StatusCode)
+		var errResp ChatResponse
+		if json.NewDecoder(resp.Body).Decode(&errResp) == nil && errResp.Error != nil {
+			msg += ": " + errResp.Error.Message
+		}
+		return nil, fmt.Errorf("%s", msg)
+	}
+	return resp, nil
+}
+
+func doWithRetry(client *http.Client, req *http.Request, body []byte) (*http.Response, error) {
+	backoff := []time.Duration{0, 1 * time.Second, 2 * time.Second}
+	var resp *http.Response
+	var err error
+	for i, wait := range backoff {
+		if i > 0 {
+			time.Sleep(wait)
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		resp, err = client.Do(req)
+		if err != nil {
+			continue
+		}
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			resp.Body.Close()
+			continue
+		}
+		return resp, nil
+	}
+	return resp, err
+}
@@ -0,0 +1,145 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+func TestSend_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q, want /v1/chat/completions", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(ChatResponse{
+			Choices: []Choice{{Message: ChatMessage{Role: "assistant", Content: "hello"}, FinishReason: "stop"}},
+			Usage:   &Usage{PromptTokens: 12, CompletionTokens: 3},
+		})
+	}))
+	defer srv.Close()
+
+	got, err := NewClient(srv.URL+"/v1/", "").Send(context.Background(), claude.NewRequest("m", "prompt"))
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if got.Text() != "hello" {
+		t.Errorf("got %q, want 'hello'", got.Text())
+	}
+	if got.Usage != (claude.Usage{InputTokens: 12, OutputTokens: 3}) {
+		t.Errorf("usage = %+v, want 12 input and 3 output tokens", got.Usage)
+	}
+	if got.StopReason != "end_turn" {
+		t.Errorf("stop reason = %q, want end_turn", got.StopReason)
+	}
+}
+
+func TestSend_Length(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ChatResponse{Choices: []Choice{{Message: ChatMessage{Content: "the start of"}, FinishReason: "length"}}})
+	}))
+	defer srv.Close()
+
+	got, err := NewClient(srv.URL, "").Send(context.Background(), claude.NewRequest("m", "prompt"))
+	if err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+	if !got.Truncated() {
+		t.Errorf("stop reason = %q, want a finish_reason of length reported as max_tokens", got.StopReason)
+	}
+}
+
+func TestSend_RequestTranslation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("Authorization = %q, want 'Bearer sk-test'", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req ChatRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		if req.Model != "local-model" || req.MaxTokens != 4096 {
+			t.Errorf("model/max_tokens = %q/%d", req.Model, req.MaxTokens)
+		}
+		if len(req.Messages) != 1 || req.Messages[0].Role != "user" || req.Messages[0].Content != "the prompt" {
+			t.Errorf("unexpected messages: %v", req.Messages)
+		}
+		json.NewEncoder(w).Encode(ChatResponse{Choices: []Choice{{Message: ChatMessage{Content: "ok"}}}})
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "sk-test").Send(context.Background(), claude.NewRequest("local-model", "the prompt")); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+}
+
+func TestToChatRequest_SystemAndSampling(t *testing.T) {
+	temp := 0.2
+	req := claude.NewRequest("m", "the prompt")
+	req.System = "You are terse."
+	req.Temperature = &temp
+	req.StopSequences = []string{"END"}
+	req.Metadata = &claude.Metadata{UserID: "u1"}
+
+	got := toChatRequest(req)
+	if len(got.Messages) != 2 || got.Messages[0].Role != "system" || got.Messages[0].Content != "You are terse." || got.Messages[1].Role != "user" {
+		t.Errorf("messages = %+v, want the system prompt first", got.Messages)
+	}
+	if got.Temperature == nil || *got.Temperature != 0.2 || got.TopP != nil || len(got.Stop) != 1 || got.User != "u1" {
+		t.Errorf("sampling = %v/%v/%v/%q", got.Temperature, got.TopP, got.Stop, got.User)
+	}
+}
+
+func TestToChatRequest_ContentBlocks(t *testing.T) {
+	req := claude.NewChatRequest("m", []claude.Message{claude.CachedMessage("user", "issues\n\n", "question")})
+	got := toChatRequest(req)
+	if len(got.Messages) != 1 || got.Messages[0].Content != "issues\n\nquestion" {
+		t.Errorf("messages = %+v, want the blocks joined into one message", got.Messages)
+	}
+}
+
+func TestSend_NoAPIKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Authorization = %q, want none", got)
+		}
+		json.NewEncoder(w).Encode(ChatResponse{Choices: []Choice{{Message: ChatMessage{Content: "ok"}}}})
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "").Send(context.Background(), claude.NewRequest("m", "p")); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+}
+
+func TestSend_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ChatResponse{Error: &APIError{Type: "invalid_request_error", Message: "model not found"}})
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, "").Send(context.Background(), claude.NewRequest("m", "p"))
+	if err == nil {
+		t.Fatal("expected error for 404 status")
+	}
+	if got := err.Error(); got != "chat completions API returned status 404: model not found" {
+		t.Errorf("error = %q", got)
+	}
+}
+
+func TestSend_NoChoices(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ChatResponse{})
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "").Send(context.Background(), claude.NewRequest("m", "p")); err == nil {
+		t.Fatal("expected error for response without choices")
+	}
+}
@@ -0,0 +1,84 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+// Stream requests a streamed completion and calls onText with each content
+// delta. If the stream fails part way, the text so far is returned along
+// with the error.
+func (c *Client) Stream(ctx context.Context, req claude.Request, onText func(string)) (*claude.Response, error) {
+	chatReq := toChatRequest(req)
+	chatReq.Stream = true
+	chatReq.StreamOptions = &StreamOptions{IncludeUsage: true}
+
+	resp, err := c.post(ctx, streamClient, chatReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return readStream(resp.Body, onText)
+}
+
+// readStream reads "data:" lines until the [DONE] sentinel. Servers send
+// one JSON chunk per line, so blank lines and other SSE fields are skipped.
+// Usage arrives in a last chunk with no choices, if the server sends it.
+func readStream(r io.Reader, onText func(string)) (*claude.Response, error) {
+	var text strings.Builder
+	var usage *Usage
+	var finishReason string
+	result := func() *claude.Response {
+		resp := textResponse(text.String())
+		resp.StopReason = toStopReason(finishReason)
+		resp.Usage = toUsage(usage)
+		return resp
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			return result(), nil
+		}
+
+		var chunk ChatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return result(), fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return result(), fmt.Errorf("chat completions stream error: %s", chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != "" {
+				finishReason = choice.FinishReason
+			}
+			if choice.Delta.Content == "" {
+				continue
+			}
+			text.WriteString(choice.Delta.Content)
+			if onText != nil {
+				onText(choice.Delta.Content)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return result(), fmt.Errorf("failed to read stream: %w", err)
+	}
+	return result(), fmt.Errorf("stream ended before [DONE]")
+}
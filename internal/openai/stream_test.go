@@ -0,0 +1,99 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+func chunk(text string) string {
+	return fmt.Sprintf("data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", text)
+}
+
+func TestStream_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req ChatRequest
+		json.Unmarshal(body, &req)
+		if !req.Stream {
+			t.Error("stream = false, want true")
+		}
+		if req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Error("stream_options.include_usage not set")
+		}
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n")
+		fmt.Fprint(w, chunk("hello"))
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, chunk(" world"))
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	var deltas []string
+	got, err := NewClient(srv.URL, "").Stream(context.Background(), claude.NewRequest("m", "p"), func(s string) {
+		deltas = append(deltas, s)
+	})
+	if err != nil {
+		t.Fatalf("Stream() error: %v", err)
+	}
+	if got.Text() != "hello world" {
+		t.Errorf("got %q, want 'hello world'", got.Text())
+	}
+	if len(deltas) != 2 {
+		t.Errorf("got %d deltas, want 2", len(deltas))
+	}
+	if got.StopReason != "end_turn" {
+		t.Errorf("stop reason = %q, want end_turn", got.StopReason)
+	}
+}
+
+func TestStream_MissingDone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, chunk("cut"))
+	}))
+	defer srv.Close()
+
+	got, err := NewClient(srv.URL, "").Stream(context.Background(), claude.NewRequest("m", "p"), nil)
+	if err == nil {
+		t.Fatal("expected error when stream ends before [DONE]")
+	}
+	if got.Text() != "cut" {
+		t.Errorf("partial text = %q, want 'cut'", got.Text())
+	}
+}
+
+func TestStream_ErrorChunk(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "data: {\"error\":{\"message\":\"out of memory\"}}\n\n")
+	}))
+	defer srv.Close()
+
+	if _, err := NewClient(srv.URL, "").Stream(context.Background(), claude.NewRequest("m", "p"), nil); err == nil {
+		t.Fatal("expected error for error chunk")
+	}
+}
+
+func TestStream_Usage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, chunk("hi"))
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":100,\"completion_tokens\":7,\"prompt_tokens_details\":{\"cached_tokens\":60}}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	got, err := NewClient(srv.URL, "").Stream(context.Background(), claude.NewRequest("m", "p"), nil)
+	if err != nil {
+		t.Fatalf("Stream() error: %v", err)
+	}
+	want := claude.Usage{InputTokens: 40, OutputTokens: 7, CacheReadInputTokens: 60}
+	if got.Usage != want {
+		t.Errorf("usage = %+v, want %+v", got.Usage, want)
+	}
+}
@@ -0,0 +1,61 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMap(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	got, err := Map(context.Background(), items, 3, func(ctx context.Context, n int) (string, error) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return fmt.Sprint(n * 10), nil
+	})
+	if err != nil {
+		t.Fatalf("Map() error: %v", err)
+	}
+	for i, n := range items {
+		if got[i] != fmt.Sprint(n*10) {
+			t.Errorf("result %d = %q, want results in the order of the items", i, got[i])
+		}
+	}
+	if peak > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", peak)
+	}
+}
+
+func TestMap_Error(t *testing.T) {
+	boom := errors.New("boom")
+	var mu sync.Mutex
+	calls := 0
+
+	_, err := Map(context.Background(), []int{1, 2, 3, 4, 5, 6}, 1, func(ctx context.Context, n int) (int, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		if n == 2 {
+			return 0, boom
+		}
+		return n, nil
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("Map() error = %v, want the first failure", err)
+	}
+	if calls > 2 {
+		t.Errorf("made %d calls, want the rest cancelled after the failure", calls)
+	}
+}
@@ -0,0 +1,112 @@
+// Package prompt renders user-chosen summary instructions from text/template
+// files, or from the built-in templates named by Builtins.
+package prompt
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/stats"
+	"github.com/mrphil/gitissuesum/internal/summarize"
+	"github.com/mrphil/gitissuesum/internal/truncate"
+)
+
+// inactiveAfter is how long an uncommented issue must go without updates to
+// count as inactive in Stats.
+const inactiveAfter = 30 * 24 * time.Hour
+
+//go:embed templates/*.tmpl
+var builtins embed.FS
+
+// Template renders the closing instructions of a summary prompt. It
+// implements summarize.Instructions.
+type Template struct {
+	tmpl *template.Template
+	now  func() time.Time
+}
+
+// Data is what a template is executed with. The embedded PromptData
+// provides .Repo, .Description, .Issues and so on.
+type Data struct {
+	summarize.PromptData
+	// Labels counts the issues under each label, most used first.
+	Labels []stats.Count
+	Stats  stats.Report
+	Now    time.Time
+}
+
+// Builtins returns the names of the built-in templates.
+func Builtins() []string {
+	entries, _ := builtins.ReadDir("templates")
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = strings.TrimSuffix(e.Name(), ".tmpl")
+	}
+	return names
+}
+
+// Load reads the template called name: a built-in if one has that name,
+// otherwise the file at that path.
+func Load(name string) (*Template, error) {
+	var text []byte
+	var err error
+	if slices.Contains(Builtins(), name) {
+		text, err = builtins.ReadFile(path.Join("templates", name+".tmpl"))
+	} else {
+		text, err = os.ReadFile(name)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no template file %s, and no built-in template of that name (built-ins: %s)", name, strings.Join(Builtins(), ", "))
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+	return Parse(name, string(text))
+}
+
+// Parse parses text as a template called name, with the functions listed in
+// the README available. Missing keys are an error rather than "<no value>".
+func Parse(name, text string) (*Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return &Template{tmpl: tmpl, now: time.Now}, nil
+}
+
+// Render executes the template with d, adding the label counts and issue
+// statistics, and returns the result with surrounding space trimmed.
+func (t *Template) Render(d summarize.PromptData) (string, error) {
+	now := t.now()
+	report := stats.Compute(d.Repo, d.Issues, now, inactiveAfter)
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, Data{PromptData: d, Labels: report.Labels, Stats: report, Now: now}); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+var funcs = template.FuncMap{
+	"truncate": truncateFunc,
+	"join":     strings.Join,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"hasLabel": func(issue summarize.Issue, label string) bool {
+		return slices.Contains(issue.Labels, label)
+	},
+	"daysSince": func(t time.Time) int {
+		return int(max(time.Since(t), 0) / (24 * time.Hour))
+	},
+}
+
+// truncateFunc is truncate.String with the length first, so templates can
+// pipe text into it: {{.Body | truncate 200}}.
+func truncateFunc(n int, s string) string {
+	return truncate.String(s, n)
+}
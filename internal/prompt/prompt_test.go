@@ -0,0 +1,113 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+var now = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func testData() summarize.PromptData {
+	return summarize.PromptData{
+		Source:      "GitHub",
+		Repo:        "o/r",
+		Description: "GitHub open issues",
+		Issues: []summarize.Issue{
+			{Number: 1, Title: "Crash on start", Body: "It crashes every time the app starts up.", Labels: []string{"bug"}, CreatedAt: now.AddDate(0, 0, -10), UpdatedAt: now},
+			{Number: 2, Title: "Dark mode", Labels: []string{"enhancement", "bug"}, CreatedAt: now.AddDate(0, 0, -40), UpdatedAt: now},
+			{Number: 3, Title: "Question", CreatedAt: now.AddDate(0, 0, -90), UpdatedAt: now.AddDate(0, 0, -60)},
+		},
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	names := Builtins()
+	for _, want := range []string{"executive", "release-planning", "triage"} {
+		if !strings.Contains(strings.Join(names, ","), want) {
+			t.Errorf("Builtins() = %v, missing %q", names, want)
+		}
+	}
+
+	for _, name := range names {
+		tmpl, err := Load(name)
+		if err != nil {
+			t.Fatalf("Load(%q) error: %v", name, err)
+		}
+		tmpl.now = func() time.Time { return now }
+		got, err := tmpl.Render(testData())
+		if err != nil {
+			t.Fatalf("Render(%q) error: %v", name, err)
+		}
+		if !strings.Contains(got, "Please provide:") {
+			t.Errorf("%s rendered without instructions:\n%s", name, got)
+		}
+	}
+}
+
+func TestRender_TriageStats(t *testing.T) {
+	tmpl, err := Load("triage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl.now = func() time.Time { return now }
+
+	got, err := tmpl.Render(testData())
+	if err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	for _, want := range []string{
+		"Unlabeled issues: 1.",
+		"Issues with no comments or updates for 30 days or more: 1.",
+		"Labels in use: bug (2), enhancement (1).",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered triage template missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestLoad_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "custom.tmpl")
+	text := `{{.Repo}} has {{len .Issues}} issues, {{.Stats.Age.MedianDays}} days old at the median.
+{{range .Issues}}{{if hasLabel . "bug"}}- #{{.Number}} {{upper .Title}}: {{.Body | truncate 10}}
+{{end}}{{end}}`
+	if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	tmpl.now = func() time.Time { return now }
+	got, err := tmpl.Render(testData())
+	if err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	want := "o/r has 3 issues, 40 days old at the median.\n- #1 CRASH ON START: It crashes...\n- #2 DARK MODE:"
+	if got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.tmpl")); err == nil || !strings.Contains(err.Error(), "built-ins: executive") {
+		t.Errorf("missing file error = %v", err)
+	}
+	if _, err := Parse("bad", "{{.Repo"); err == nil {
+		t.Error("Parse() accepted a malformed template")
+	}
+
+	tmpl, err := Parse("unknown field", "{{.Nope}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmpl.Render(testData()); err == nil {
+		t.Error("Render() should fail on an unknown field")
+	}
+}
@@ -0,0 +1,77 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mrphil/gitissuesum/internal/truncate"
+)
+
+// maxBarWidth is the length of the longest histogram bar.
+const maxBarWidth = 40
+
+// WriteText renders r as a series of tables. Issue lists are cut to topN
+// entries; the JSON form has them in full.
+func WriteText(w io.Writer, r Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Repository:\t%s\n", r.Repository)
+	fmt.Fprintf(tw, "Issues:\t%d\n", r.IssueCount)
+	if r.IssueCount > 0 {
+		fmt.Fprintf(tw, "Age (days):\tmedian %d, p75 %d, p90 %d, max %d\n", r.Age.MedianDays, r.Age.P75Days, r.Age.P90Days, r.Age.MaxDays)
+	}
+
+	section(tw, "AGE\tISSUES\t")
+	peak := 0
+	for _, b := range r.Age.Histogram {
+		peak = max(peak, b.Count)
+	}
+	for _, b := range r.Age.Histogram {
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", b.Name, b.Count, bar(b.Count, peak))
+	}
+
+	section(tw, "LABEL\tISSUES")
+	for _, c := range r.Labels {
+		fmt.Fprintf(tw, "%s\t%d\n", c.Name, c.Count)
+	}
+	fmt.Fprintf(tw, "(unlabeled)\t%d\n", len(r.Unlabeled))
+
+	section(tw, "AUTHOR\tISSUES")
+	for _, c := range r.TopAuthors {
+		fmt.Fprintf(tw, "%s\t%d\n", c.Name, c.Count)
+	}
+
+	writeIssues(tw, "MOST COMMENTED", r.MostCommented)
+	writeIssues(tw, "UNLABELED", r.Unlabeled)
+	writeIssues(tw, fmt.Sprintf("NO ACTIVITY IN %d DAYS", r.InactiveDays), r.Inactive)
+
+	return tw.Flush()
+}
+
+func section(w io.Writer, header string) {
+	fmt.Fprintf(w, "\n%s\n", header)
+}
+
+func writeIssues(w io.Writer, heading string, refs []IssueRef) {
+	section(w, heading+"\tCOMMENTS\tAGE (DAYS)")
+	if len(refs) == 0 {
+		fmt.Fprintln(w, "(none)\t\t")
+		return
+	}
+	for _, ref := range refs[:min(len(refs), topN)] {
+		fmt.Fprintf(w, "#%d %s\t%d\t%d\n", ref.Number, truncate.String(ref.Title, 60), ref.Comments, ref.AgeDays)
+	}
+	if more := len(refs) - topN; more > 0 {
+		fmt.Fprintf(w, "(%d more)\t\t\n", more)
+	}
+}
+
+func bar(n, peak int) string {
+	if peak == 0 {
+		return ""
+	}
+	width := (n*maxBarWidth + peak - 1) / peak
+	return strings.Repeat("#", width)
+}
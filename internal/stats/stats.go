@@ -0,0 +1,189 @@
+// Package stats computes an issue report from fetched issues without
+// calling a language model.
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+// topN caps the author and most-commented lists.
+const topN = 10
+
+const day = 24 * time.Hour
+
+// ageBuckets are the upper bounds of the age histogram; the last bucket
+// holds everything older.
+var ageBuckets = []ageBucket{
+	{"< 1 week", 7 * day},
+	{"1-4 weeks", 28 * day},
+	{"1-3 months", 91 * day},
+	{"3-12 months", 365 * day},
+	{"1-2 years", 730 * day},
+	{"> 2 years", 0},
+}
+
+type ageBucket struct {
+	Label string
+	Max   time.Duration
+}
+
+type Report struct {
+	Repository  string    `json:"repository"`
+	IssueCount  int       `json:"issue_count"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Labels      []Count   `json:"labels"`
+	Age         Age       `json:"age"`
+	TopAuthors  []Count   `json:"top_authors"`
+	// MostCommented holds the issues with the most comments, busiest first.
+	MostCommented []IssueRef `json:"most_commented"`
+	Unlabeled     []IssueRef `json:"unlabeled"`
+	// Inactive holds issues nobody has commented on that have not been
+	// updated for at least InactiveDays.
+	InactiveDays int        `json:"inactive_days"`
+	Inactive     []IssueRef `json:"inactive"`
+}
+
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Age describes how long ago the issues were opened, in whole days.
+type Age struct {
+	MedianDays int     `json:"median_days"`
+	P75Days    int     `json:"p75_days"`
+	P90Days    int     `json:"p90_days"`
+	MaxDays    int     `json:"max_days"`
+	Histogram  []Count `json:"histogram"`
+}
+
+type IssueRef struct {
+	Number   int    `json:"number"`
+	Title    string `json:"title"`
+	Comments int    `json:"comments"`
+	AgeDays  int    `json:"age_days"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Compute builds the report for issues as of now. Issues count as inactive
+// when they have no comments and were last updated at least inactiveAfter
+// ago.
+func Compute(repo string, issues []summarize.Issue, now time.Time, inactiveAfter time.Duration) Report {
+	r := Report{
+		Repository:    repo,
+		IssueCount:    len(issues),
+		GeneratedAt:   now.UTC(),
+		Labels:        []Count{},
+		TopAuthors:    []Count{},
+		MostCommented: []IssueRef{},
+		Unlabeled:     []IssueRef{},
+		InactiveDays:  int(inactiveAfter / day),
+		Inactive:      []IssueRef{},
+	}
+
+	labels := map[string]int{}
+	authors := map[string]int{}
+	ages := make([]time.Duration, 0, len(issues))
+	for _, issue := range issues {
+		for _, l := range issue.Labels {
+			labels[l]++
+		}
+		if issue.Author != "" {
+			authors[issue.Author]++
+		}
+		ages = append(ages, max(now.Sub(issue.CreatedAt), 0))
+
+		ref := newRef(issue, now)
+		if len(issue.Labels) == 0 {
+			r.Unlabeled = append(r.Unlabeled, ref)
+		}
+		if issue.Comments == 0 && now.Sub(issue.UpdatedAt) >= inactiveAfter {
+			r.Inactive = append(r.Inactive, ref)
+		}
+		if issue.Comments > 0 {
+			r.MostCommented = append(r.MostCommented, ref)
+		}
+	}
+
+	r.Labels = sortedCounts(labels, 0)
+	r.TopAuthors = sortedCounts(authors, topN)
+	r.Age = ageStats(ages)
+
+	slices.SortStableFunc(r.MostCommented, func(a, b IssueRef) int {
+		return cmp.Compare(b.Comments, a.Comments)
+	})
+	if len(r.MostCommented) > topN {
+		r.MostCommented = r.MostCommented[:topN]
+	}
+	// Oldest first, since those have waited longest for attention.
+	slices.SortStableFunc(r.Inactive, func(a, b IssueRef) int {
+		return cmp.Compare(b.AgeDays, a.AgeDays)
+	})
+	return r
+}
+
+func newRef(issue summarize.Issue, now time.Time) IssueRef {
+	return IssueRef{
+		Number:   issue.Number,
+		Title:    issue.Title,
+		Comments: issue.Comments,
+		AgeDays:  int(max(now.Sub(issue.CreatedAt), 0) / day),
+		URL:      issue.URL,
+	}
+}
+
+// sortedCounts orders counts from most to least frequent, breaking ties by
+// name, and keeps at most limit of them when limit is positive.
+func sortedCounts(m map[string]int, limit int) []Count {
+	counts := make([]Count, 0, len(m))
+	for name, n := range m {
+		counts = append(counts, Count{Name: name, Count: n})
+	}
+	slices.SortFunc(counts, func(a, b Count) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	if limit > 0 && len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts
+}
+
+func ageStats(ages []time.Duration) Age {
+	a := Age{Histogram: make([]Count, len(ageBuckets))}
+	for i, b := range ageBuckets {
+		a.Histogram[i].Name = b.Label
+	}
+	if len(ages) == 0 {
+		return a
+	}
+
+	for _, age := range ages {
+		i := slices.IndexFunc(ageBuckets[:len(ageBuckets)-1], func(b ageBucket) bool {
+			return age < b.Max
+		})
+		if i < 0 {
+			i = len(ageBuckets) - 1
+		}
+		a.Histogram[i].Count++
+	}
+
+	slices.Sort(ages)
+	a.MedianDays = percentile(ages, 50)
+	a.P75Days = percentile(ages, 75)
+	a.P90Days = percentile(ages, 90)
+	a.MaxDays = int(ages[len(ages)-1] / day)
+	return a
+}
+
+// percentile returns the nearest-rank pth percentile of sorted, in days.
+func percentile(sorted []time.Duration, p int) int {
+	rank := (p*len(sorted) + 99) / 100
+	return int(sorted[max(rank, 1)-1] / day)
+}
@@ -0,0 +1,96 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/summarize"
+)
+
+var now = time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+
+func daysAgo(n int) time.Time {
+	return now.Add(-time.Duration(n) * day)
+}
+
+func testIssues() []summarize.Issue {
+	return []summarize.Issue{
+		{Number: 1, Title: "Crash", Author: "alice", Labels: []string{"bug"}, Comments: 5, CreatedAt: daysAgo(2), UpdatedAt: daysAgo(1)},
+		{Number: 2, Title: "Slow", Author: "bob", Labels: []string{"bug", "perf"}, Comments: 9, CreatedAt: daysAgo(40), UpdatedAt: daysAgo(3)},
+		{Number: 3, Title: "Docs", Author: "alice", CreatedAt: daysAgo(200), UpdatedAt: daysAgo(200)},
+		{Number: 4, Title: "Idea", Author: "carol", CreatedAt: daysAgo(10), UpdatedAt: daysAgo(5)},
+	}
+}
+
+func TestCompute_Counts(t *testing.T) {
+	r := Compute("o/r", testIssues(), now, 30*day)
+
+	if r.IssueCount != 4 {
+		t.Errorf("IssueCount = %d, want 4", r.IssueCount)
+	}
+	if len(r.Labels) != 2 || r.Labels[0] != (Count{"bug", 2}) || r.Labels[1] != (Count{"perf", 1}) {
+		t.Errorf("Labels = %v, want bug:2 perf:1", r.Labels)
+	}
+	if len(r.TopAuthors) != 3 || r.TopAuthors[0] != (Count{"alice", 2}) {
+		t.Errorf("TopAuthors = %v, want alice first with 2", r.TopAuthors)
+	}
+	if len(r.MostCommented) != 2 || r.MostCommented[0].Number != 2 || r.MostCommented[1].Number != 1 {
+		t.Errorf("MostCommented = %v, want #2 then #1", r.MostCommented)
+	}
+	if len(r.Unlabeled) != 2 {
+		t.Errorf("got %d unlabeled issues, want 2", len(r.Unlabeled))
+	}
+	if len(r.Inactive) != 1 || r.Inactive[0].Number != 3 {
+		t.Errorf("Inactive = %v, want only #3", r.Inactive)
+	}
+}
+
+func TestCompute_Age(t *testing.T) {
+	r := Compute("o/r", testIssues(), now, 30*day)
+
+	if r.Age.MedianDays != 10 || r.Age.P90Days != 200 || r.Age.MaxDays != 200 {
+		t.Errorf("Age = %+v, want median 10, p90 200, max 200", r.Age)
+	}
+	want := []int{1, 1, 1, 1, 0, 0}
+	for i, b := range r.Age.Histogram {
+		if b.Count != want[i] {
+			t.Errorf("bucket %q = %d, want %d", b.Name, b.Count, want[i])
+		}
+	}
+}
+
+func TestCompute_Empty(t *testing.T) {
+	r := Compute("o/r", nil, now, 30*day)
+
+	if r.Labels == nil || r.Unlabeled == nil || r.Inactive == nil {
+		t.Error("empty report should use empty lists, not null")
+	}
+	if len(r.Age.Histogram) != len(ageBuckets) {
+		t.Errorf("got %d histogram buckets, want %d", len(r.Age.Histogram), len(ageBuckets))
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	ages := []time.Duration{1 * day, 2 * day, 3 * day, 4 * day}
+	if got := percentile(ages, 50); got != 2 {
+		t.Errorf("percentile(50) = %d, want 2", got)
+	}
+	if got := percentile(ages, 90); got != 4 {
+		t.Errorf("percentile(90) = %d, want 4", got)
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	var b strings.Builder
+	if err := WriteText(&b, Compute("o/r", testIssues(), now, 30*day)); err != nil {
+		t.Fatalf("WriteText() error: %v", err)
+	}
+	got := b.String()
+
+	for _, want := range []string{"Issues:", "median 10", "LABEL", "(unlabeled)", "#2 Slow", "NO ACTIVITY IN 30 DAYS", "#3 Docs"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package summarize
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+const (
+	// maxBodyCeiling and maxDigestCeiling cap how much of an issue's body
+	// and discussion the budgeter includes, however much room there is.
+	maxBodyCeiling   = 4000
+	maxDigestCeiling = 6000
+	// minDetailChars is the least the budgeter cuts a body or digest to
+	// before giving up on a single prompt and splitting the issues into
+	// batches instead.
+	minDetailChars = 150
+	// maxFitAttempts bounds how often the budget is tightened when an exact
+	// token count shows the estimate was low.
+	maxFitAttempts = 3
+)
+
+// defaultContextWindow is assumed for models missing from contextWindows,
+// which are usually local models behind an OpenAI-compatible server.
+const defaultContextWindow = 32000
+
+// contextWindows lists the context window of known models by name prefix,
+// most specific first.
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"claude-", 200000},
+	{"gpt-4.1", 1000000},
+	{"gpt-4o", 128000},
+	{"gpt-5", 400000},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4", 200000},
+}
+
+// TokenCounter is implemented by providers that can count a request's
+// input tokens exactly. Wrappers return errors.ErrUnsupported when what
+// they wrap can't.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, req claude.Request) (int, error)
+}
+
+// detail caps how many characters of an issue's body and comment digest
+// are shown.
+type detail struct {
+	body, digest int
+}
+
+// defaultDetail applies where the budgeter hasn't chosen, such as in
+// batches and change summaries.
+var defaultDetail = detail{body: maxBodyChars, digest: maxDigestChars}
+
+// trimReport tallies what the budgeter cut to fit the budget.
+type trimReport struct {
+	bodies, digests, tokens int
+}
+
+// contextWindow returns the context window of model, in tokens.
+func contextWindow(model string) int {
+	for _, w := range contextWindows {
+		if strings.HasPrefix(model, w.prefix) {
+			return w.tokens
+		}
+	}
+	return defaultContextWindow
+}
+
+// inputBudget is the most input tokens one request may use: opts.InputBudget
+// if set, otherwise three quarters of the model's context window, which
+// leaves room for the response and for error in the estimates.
+func inputBudget(opts Options) int {
+	if opts.InputBudget > 0 {
+		return opts.InputBudget
+	}
+	return contextWindow(opts.Model) * 3 / 4
+}
+
+// fitPrompt builds the summary prompt within the input budget, giving issue
+// bodies and comment digests as much room as the budget allows. If the
+// issues don't fit even with those trimmed, they are condensed in batches
+// first.
+func fitPrompt(ctx context.Context, opts Options, subj subject, issues []Issue, instructions string) (string, error) {
+	budget := inputBudget(opts)
+	target := budget
+	for attempt := 1; ; attempt++ {
+		details, trimmed, ok := allocateDetail(subj, issues, target-promptOverhead-estimateTokens(instructions))
+		if !ok {
+			logf("%d issues don't fit in the %d-token input budget, so they will be summarized in batches.\n", len(issues), budget)
+			batches := splitBatches(subj, issues, budget)
+			if len(batches) < 2 {
+				return buildPrompt(subj, issues, instructions), nil
+			}
+			prompt, err := condenseBatches(ctx, opts, subj, len(issues), batches, instructions)
+			if err != nil {
+				return "", fmt.Errorf("failed to get summary: %w", err)
+			}
+			return prompt, nil
+		}
+
+		subj.Detail = details
+		prompt := buildPrompt(subj, issues, instructions)
+		n, err := countTokens(ctx, opts, prompt)
+		if err != nil {
+			return "", err
+		}
+		if n > budget && attempt < maxFitAttempts {
+			// The estimate was low; aim lower by the same ratio.
+			target = target * budget / n
+			continue
+		}
+		if n > budget {
+			logf("Warning: the prompt is %d tokens, over the %d-token input budget.\n", n, budget)
+		}
+		if trimmed.bodies > 0 || trimmed.digests > 0 {
+			logf("Trimmed %d issue bodies and %d comment digests by about %d tokens to fit the %d-token input budget.\n",
+				trimmed.bodies, trimmed.digests, trimmed.tokens, budget)
+		}
+		return prompt, nil
+	}
+}
+
+// countTokens counts the prompt's tokens exactly if opts.CountTokens is set
+// and the provider can, and estimates them otherwise.
+func countTokens(ctx context.Context, opts Options, prompt string) (int, error) {
+	estimate := estimateTokens(prompt)
+	if !opts.CountTokens {
+		return estimate, nil
+	}
+	counter, ok := opts.Provider.(TokenCounter)
+	if !ok {
+		logf("This provider can't count tokens, so the prompt size is estimated.\n")
+		return estimate, nil
+	}
+	n, err := counter.CountTokens(ctx, NewRequest(opts, prompt))
+	if errors.Is(err, errors.ErrUnsupported) {
+		logf("This provider can't count tokens, so the prompt size is estimated.\n")
+		return estimate, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	logf("The prompt is %d tokens (estimated %d).\n", n, estimate)
+	return n, nil
+}
+
+// allocateDetail shares budget tokens between the issues' bodies and
+// comment digests. Everything fixed about an issue, such as its title and
+// labels, is paid for first; the rest is split evenly, with any text
+// shorter than its share passing what it doesn't need on to the others.
+// It reports false if that would cut some text below minDetailChars.
+func allocateDetail(subj subject, issues []Issue, budget int) (map[int]detail, trimReport, bool) {
+	type want struct {
+		issue, chars int
+		digest       bool
+	}
+
+	var wants []want
+	available := budget
+	for i, issue := range issues {
+		comments := subj.Comments[issue.Number]
+		available -= issueTokens(issue, comments, detail{})
+		wants = append(wants, want{issue: i, chars: min(len(strings.TrimSpace(issue.Body)), maxBodyCeiling)})
+		if len(comments) > 0 {
+			wants = append(wants, want{issue: i, chars: min(digestLen(comments), maxDigestCeiling), digest: true})
+		}
+	}
+	if available < 0 {
+		return nil, trimReport{}, false
+	}
+
+	slices.SortStableFunc(wants, func(a, b want) int {
+		return cmp.Compare(a.chars, b.chars)
+	})
+	chars := available * 4
+	details := make(map[int]detail, len(issues))
+	var trimmed trimReport
+	for i, w := range wants {
+		given := min(w.chars, chars/(len(wants)-i))
+		if given < min(w.chars, minDetailChars) {
+			return nil, trimReport{}, false
+		}
+		chars -= given
+
+		n := issues[w.issue].Number
+		d := details[n]
+		if w.digest {
+			d.digest = given
+		} else {
+			d.body = given
+		}
+		details[n] = d
+
+		if given < w.chars {
+			if w.digest {
+				trimmed.digests++
+			} else {
+				trimmed.bodies++
+			}
+			trimmed.tokens += (w.chars - given + 3) / 4
+		}
+	}
+	return details, trimmed, true
+}
@@ -0,0 +1,149 @@
+package summarize
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+func TestInputBudget(t *testing.T) {
+	tests := []struct {
+		opts Options
+		want int
+	}{
+		{Options{Model: "claude-sonnet-4-20250514"}, 150000},
+		{Options{Model: "gpt-4o-mini"}, 96000},
+		{Options{Model: "llama3.1:8b"}, 24000},
+		{Options{Model: "claude-sonnet-4-20250514", InputBudget: 5000}, 5000},
+	}
+	for _, tt := range tests {
+		if got := inputBudget(tt.opts); got != tt.want {
+			t.Errorf("inputBudget(%+v) = %d, want %d", tt.opts, got, tt.want)
+		}
+	}
+}
+
+func TestAllocateDetail_Roomy(t *testing.T) {
+	long := strings.Repeat("x", 3000)
+	issues := []Issue{testIssue(1, long), testIssue(2, "short")}
+
+	details, trimmed, ok := allocateDetail(subject{}, issues, 100000)
+	if !ok {
+		t.Fatal("allocateDetail() should fit")
+	}
+	if details[1].body != 3000 || details[2].body != 5 {
+		t.Errorf("details = %+v, want bodies in full", details)
+	}
+	if trimmed != (trimReport{}) {
+		t.Errorf("trimmed = %+v, want nothing", trimmed)
+	}
+
+	var b strings.Builder
+	writeIssue(&b, issues[0], nil, details[1])
+	if !strings.Contains(b.String(), long) {
+		t.Error("a body longer than the default limit should be shown in full when there is room")
+	}
+}
+
+func TestAllocateDetail_Trims(t *testing.T) {
+	issues := []Issue{
+		testIssue(1, strings.Repeat("a", 2000)),
+		testIssue(2, strings.Repeat("b", 2000)),
+		testIssue(3, strings.Repeat("c", 100)),
+	}
+	comments := map[int][]Comment{3: {testComment("m", true, strings.Repeat("d", 250))}}
+	subj := subject{Comments: comments}
+
+	fixed := 0
+	for _, issue := range issues {
+		fixed += issueTokens(issue, comments[issue.Number], detail{})
+	}
+	// Room for 1000 characters of text.
+	details, trimmed, ok := allocateDetail(subj, issues, fixed+250)
+	if !ok {
+		t.Fatal("allocateDetail() should fit")
+	}
+	if details[3].body != 100 {
+		t.Errorf("short body got %d chars, want all 100", details[3].body)
+	}
+	if d := details[1].body - details[2].body; d < -1 || d > 1 || details[1].body >= 2000 {
+		t.Errorf("long bodies got %d and %d chars, want equal trimmed shares", details[1].body, details[2].body)
+	}
+	if total := details[1].body + details[2].body + details[3].body + details[3].digest; total > 1000 {
+		t.Errorf("allocated %d chars, over the 1000 available", total)
+	}
+	if trimmed.bodies != 2 || trimmed.digests != 0 || trimmed.tokens == 0 {
+		t.Errorf("trimmed = %+v, want the two long bodies", trimmed)
+	}
+}
+
+func TestAllocateDetail_TooTight(t *testing.T) {
+	var issues []Issue
+	fixed := 0
+	for i := range 50 {
+		issues = append(issues, testIssue(i+1, strings.Repeat("x", 1000)))
+		fixed += issueTokens(issues[i], nil, detail{})
+	}
+	// Room for 100 characters of each body.
+	if _, _, ok := allocateDetail(subject{}, issues, fixed+50*100/4); ok {
+		t.Error("allocateDetail() should give up rather than cut bodies to almost nothing")
+	}
+	if _, _, ok := allocateDetail(subject{}, issues, 10); ok {
+		t.Error("allocateDetail() should fail when the issues alone don't fit")
+	}
+}
+
+// countingProvider reports token counts scaled up from the estimate.
+type countingProvider struct {
+	fakeProvider
+	factor float64
+	counts []int
+}
+
+func (c *countingProvider) CountTokens(ctx context.Context, req claude.Request) (int, error) {
+	n := int(float64(estimateTokens(req.Messages[0].Content)) * c.factor)
+	c.counts = append(c.counts, n)
+	return n, nil
+}
+
+func TestFitPrompt_ExactCount(t *testing.T) {
+	var issues []Issue
+	for i := range 10 {
+		issues = append(issues, testIssue(i+1, strings.Repeat("x", 2000)))
+	}
+	provider := &countingProvider{factor: 1.5}
+	opts := Options{Provider: provider, Model: "m", InputBudget: 6000, CountTokens: true}
+
+	prompt, err := fitPrompt(context.Background(), opts, subject{Repo: "o/r"}, issues, summaryInstructions)
+	if err != nil {
+		t.Fatalf("fitPrompt() error: %v", err)
+	}
+	if len(provider.counts) < 2 {
+		t.Fatalf("counted %d times, want a recount after the first came in over budget", len(provider.counts))
+	}
+	if last := provider.counts[len(provider.counts)-1]; last > 6000 {
+		t.Errorf("final count %d is over the 6000-token budget", last)
+	}
+	if len(provider.prompts) != 0 || !strings.Contains(prompt, "--- Issue #10 ---") {
+		t.Error("the issues should fit in one prompt without batching")
+	}
+}
+
+func TestFitPrompt_Batches(t *testing.T) {
+	var issues []Issue
+	for i := range 40 {
+		issues = append(issues, testIssue(i+1, strings.Repeat("x", 400)))
+	}
+	fake := &fakeProvider{replies: []string{"part one", "part two", "part three", "part four"}}
+	opts := Options{Provider: fake, Model: "m", InputBudget: 3000}
+
+	prompt, err := fitPrompt(context.Background(), opts, subject{Repo: "o/r"}, issues, summaryInstructions)
+	if err != nil {
+		t.Fatalf("fitPrompt() error: %v", err)
+	}
+	if len(fake.prompts) < 2 || !strings.Contains(prompt, "--- Part 1 ---") {
+		t.Errorf("expected the issues to be condensed in batches, sent %d requests", len(fake.prompts))
+	}
+}
@@ -0,0 +1,216 @@
+package summarize
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// minNewComments is how many comments an issue must gain between
+	// snapshots to count as heating up.
+	minNewComments = 2
+	// maxListedChanges caps each list of changes in the prompt.
+	maxListedChanges = 50
+)
+
+const changesInstructions = `Please provide:
+1. A short overview of how the issue tracker changed over this period (2-3 sentences)
+2. The most notable newly opened issues and what they have in common
+3. What got closed, and whether it points to areas being fixed
+4. Issues heating up and why they may need attention soon
+5. Anything the relabeling suggests about triage or priorities
+
+Focus on what changed, not on the backlog as a whole. Be concise and actionable.`
+
+// Snapshot records the issues fetched by a run and the summary written
+// for them, so a later run can report what changed.
+type Snapshot struct {
+	Source     string    `json:"source"`
+	Repository string    `json:"repository"`
+	State      string    `json:"state,omitempty"`
+	TakenAt    time.Time `json:"taken_at"`
+	Issues     []Issue   `json:"issues"`
+	Summary    string    `json:"summary,omitempty"`
+}
+
+// Changes is the difference between a snapshot and the current issues.
+type Changes struct {
+	Since  time.Time `json:"since"`
+	Opened []Issue   `json:"opened"`
+	// Closed holds issues that are now closed or no longer match the
+	// filter, which for open issues usually means the same thing.
+	Closed    []Issue    `json:"closed"`
+	Relabeled []Relabel  `json:"relabeled"`
+	HeatingUp []Activity `json:"heating_up"`
+}
+
+type Relabel struct {
+	Issue   Issue    `json:"issue"`
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+type Activity struct {
+	Issue       Issue `json:"issue"`
+	NewComments int   `json:"new_comments"`
+}
+
+// ChangeReport is the --output json form of a change summary.
+type ChangeReport struct {
+	Repository string       `json:"repository"`
+	IssueCount int          `json:"issue_count"`
+	Changes    *Changes     `json:"changes"`
+	Summary    string       `json:"summary"`
+	Usage      *UsageReport `json:"usage,omitempty"`
+}
+
+func (c *Changes) empty() bool {
+	return len(c.Opened) == 0 && len(c.Closed) == 0 && len(c.Relabeled) == 0 && len(c.HeatingUp) == 0
+}
+
+// compareSnapshots works out what changed from prev to the current issues.
+func compareSnapshots(prev *Snapshot, issues []Issue) *Changes {
+	c := &Changes{Since: prev.TakenAt, Opened: []Issue{}, Closed: []Issue{}, Relabeled: []Relabel{}, HeatingUp: []Activity{}}
+
+	before := make(map[int]Issue, len(prev.Issues))
+	for _, issue := range prev.Issues {
+		before[issue.Number] = issue
+	}
+	now := make(map[int]bool, len(issues))
+
+	for _, issue := range issues {
+		now[issue.Number] = true
+		old, ok := before[issue.Number]
+		if !ok {
+			if issue.State != "closed" {
+				c.Opened = append(c.Opened, issue)
+			}
+			continue
+		}
+		if issue.State == "closed" && old.State != "closed" {
+			c.Closed = append(c.Closed, issue)
+			continue
+		}
+		if added, removed := labelDiff(old.Labels, issue.Labels); len(added) > 0 || len(removed) > 0 {
+			c.Relabeled = append(c.Relabeled, Relabel{Issue: issue, Added: added, Removed: removed})
+		}
+		if n := issue.Comments - old.Comments; n >= minNewComments {
+			c.HeatingUp = append(c.HeatingUp, Activity{Issue: issue, NewComments: n})
+		}
+	}
+	for _, old := range prev.Issues {
+		if !now[old.Number] && old.State != "closed" {
+			c.Closed = append(c.Closed, old)
+		}
+	}
+
+	slices.SortStableFunc(c.HeatingUp, func(a, b Activity) int {
+		return cmp.Compare(b.NewComments, a.NewComments)
+	})
+	return c
+}
+
+func labelDiff(before, after []string) (added, removed []string) {
+	for _, l := range after {
+		if !slices.Contains(before, l) {
+			added = append(added, l)
+		}
+	}
+	for _, l := range before {
+		if !slices.Contains(after, l) {
+			removed = append(removed, l)
+		}
+	}
+	return added, removed
+}
+
+// summarizeChanges compares the issues against opts.Previous and asks for a
+// summary of just the changes, returning its text.
+func summarizeChanges(ctx context.Context, opts Options, subj subject, issues []Issue) (string, error) {
+	changes := compareSnapshots(opts.Previous, issues)
+	logf("Since %s: %d opened, %d closed, %d relabeled, %d heating up.\n",
+		changes.Since.Local().Format("2006-01-02 15:04"), len(changes.Opened), len(changes.Closed), len(changes.Relabeled), len(changes.HeatingUp))
+
+	report := &ChangeReport{Repository: subj.Repo, IssueCount: len(issues), Changes: changes}
+	if changes.empty() {
+		logf("Nothing changed since the last snapshot.\n")
+		if opts.Output == OutputJSON {
+			report.Usage = usageReport(opts)
+			return "", WriteJSON(report)
+		}
+		return "", nil
+	}
+
+	logf("Sending changes to %s for analysis...\n", opts.Model)
+	prompt := buildChangesPrompt(subj, len(issues), changes)
+	if isDryRun(opts) {
+		_, err := complete(ctx, opts, prompt)
+		return "", err
+	}
+
+	if opts.Output == OutputJSON {
+		text, err := complete(ctx, opts, prompt)
+		if err != nil {
+			return "", err
+		}
+		report.Summary = strings.TrimSpace(text)
+		report.Usage = usageReport(opts)
+		return report.Summary, WriteJSON(report)
+	}
+
+	fmt.Println()
+	text, err := completeStream(ctx, opts, prompt, func(text string) {
+		fmt.Print(text)
+	})
+	fmt.Println()
+	return text, err
+}
+
+func buildChangesPrompt(subj subject, total int, c *Changes) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "This is how the %s of the repository %s changed since %s.\n", subj.describe(), subj.Repo, c.Since.Format("2006-01-02"))
+	fmt.Fprintf(&b, "There are now %d %s.\n\n", total, subj.issues())
+
+	writeChangeList(&b, "Newly opened", c.Opened, func(b *strings.Builder, issue Issue) {
+		writeIssue(b, issue, nil, defaultDetail)
+	})
+	writeChangeList(&b, "Closed or no longer matching", c.Closed, func(b *strings.Builder, issue Issue) {
+		fmt.Fprintf(b, "- #%d %s\n", issue.Number, issue.Title)
+	})
+	writeChangeList(&b, "Relabeled", c.Relabeled, func(b *strings.Builder, r Relabel) {
+		fmt.Fprintf(b, "- #%d %s:", r.Issue.Number, r.Issue.Title)
+		if len(r.Added) > 0 {
+			fmt.Fprintf(b, " added %s", strings.Join(r.Added, ", "))
+		}
+		if len(r.Removed) > 0 {
+			fmt.Fprintf(b, " removed %s", strings.Join(r.Removed, ", "))
+		}
+		b.WriteString("\n")
+	})
+	writeChangeList(&b, "Heating up", c.HeatingUp, func(b *strings.Builder, a Activity) {
+		fmt.Fprintf(b, "- #%d %s: %d new comments (%d total)\n", a.Issue.Number, a.Issue.Title, a.NewComments, a.Issue.Comments)
+	})
+
+	b.WriteString(changesInstructions)
+
+	return b.String()
+}
+
+func writeChangeList[T any](b *strings.Builder, heading string, items []T, write func(*strings.Builder, T)) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "=== %s (%d) ===\n", heading, len(items))
+	for _, item := range items[:min(len(items), maxListedChanges)] {
+		write(b, item)
+	}
+	if more := len(items) - maxListedChanges; more > 0 {
+		fmt.Fprintf(b, "(%d more not shown)\n", more)
+	}
+	b.WriteString("\n")
+}
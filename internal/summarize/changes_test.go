@@ -0,0 +1,126 @@
+package summarize
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSource serves a fixed set of issues, or fails with err.
+type fakeSource struct {
+	repo   string
+	issues []Issue
+	err    error
+}
+
+func (f *fakeSource) Name() string { return "GitHub" }
+
+func (f *fakeSource) Repo() string {
+	if f.repo == "" {
+		return "o/r"
+	}
+	return f.repo
+}
+
+func (f *fakeSource) FetchIssues(ctx context.Context, filter Filter, maxIssues int) ([]Issue, error) {
+	return f.issues, f.err
+}
+
+func (f *fakeSource) FetchComments(ctx context.Context, numbers []int) (map[int][]Comment, error) {
+	return nil, nil
+}
+
+var snapshotTime = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func previousSnapshot() *Snapshot {
+	return &Snapshot{
+		TakenAt: snapshotTime,
+		Issues: []Issue{
+			{Number: 1, Title: "Crash", State: "open", Labels: []string{"bug"}, Comments: 1},
+			{Number: 2, Title: "Slow", State: "open", Comments: 0},
+			{Number: 3, Title: "Typo", State: "open"},
+			{Number: 4, Title: "Old", State: "open"},
+		},
+	}
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	current := []Issue{
+		{Number: 1, Title: "Crash", State: "open", Labels: []string{"bug", "p1"}, Comments: 6},
+		{Number: 2, Title: "Slow", State: "open", Comments: 1},
+		{Number: 3, Title: "Typo", State: "closed"},
+		{Number: 5, Title: "New", State: "open"},
+	}
+
+	c := compareSnapshots(previousSnapshot(), current)
+
+	numbers := func(issues []Issue) []int {
+		var n []int
+		for _, issue := range issues {
+			n = append(n, issue.Number)
+		}
+		return n
+	}
+	if got := numbers(c.Opened); !slices.Equal(got, []int{5}) {
+		t.Errorf("opened = %v, want [5]", got)
+	}
+	if got := numbers(c.Closed); !slices.Equal(got, []int{3, 4}) {
+		t.Errorf("closed = %v, want [3 4]", got)
+	}
+	if len(c.Relabeled) != 1 || c.Relabeled[0].Issue.Number != 1 || !slices.Equal(c.Relabeled[0].Added, []string{"p1"}) {
+		t.Errorf("relabeled = %+v, want #1 gaining p1", c.Relabeled)
+	}
+	if len(c.HeatingUp) != 1 || c.HeatingUp[0].Issue.Number != 1 || c.HeatingUp[0].NewComments != 5 {
+		t.Errorf("heating up = %+v, want #1 with 5 new comments", c.HeatingUp)
+	}
+}
+
+func TestBuildChangesPrompt(t *testing.T) {
+	c := &Changes{
+		Since:     snapshotTime,
+		Opened:    []Issue{{Number: 5, Title: "New", Author: "a"}},
+		Relabeled: []Relabel{{Issue: Issue{Number: 1, Title: "Crash"}, Removed: []string{"triage"}}},
+	}
+
+	prompt := buildChangesPrompt(subject{Source: "GitHub", Repo: "o/r"}, 3, c)
+
+	for _, want := range []string{"changed since 2025-06-01", "=== Newly opened (1) ===", "Issue #5", "#1 Crash: removed triage", changesInstructions} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+	if strings.Contains(prompt, "Heating up") {
+		t.Error("empty change lists should be left out")
+	}
+}
+
+func TestRun_SinceLast(t *testing.T) {
+	fake := &fakeProvider{replies: []string{"Two issues closed."}}
+	src := &fakeSource{issues: []Issue{{Number: 1, Title: "Crash", State: "open", Labels: []string{"bug"}, Comments: 1}}}
+
+	snap, err := Run(context.Background(), Options{Source: src, Provider: fake, Model: "m", Previous: previousSnapshot()})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(fake.prompts) != 1 || !strings.Contains(fake.prompts[0], "Closed or no longer matching (3)") {
+		t.Errorf("expected one change-focused request, got %q", fake.prompts)
+	}
+	if snap.Summary != "Two issues closed." || len(snap.Issues) != 1 || snap.Repository != "o/r" {
+		t.Errorf("snapshot = %+v", snap)
+	}
+}
+
+func TestRun_SinceLastNoChanges(t *testing.T) {
+	fake := &fakeProvider{}
+	prev := previousSnapshot()
+	src := &fakeSource{issues: prev.Issues}
+
+	if _, err := Run(context.Background(), Options{Source: src, Provider: fake, Model: "m", Previous: prev}); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(fake.prompts) != 0 {
+		t.Errorf("sent %d requests, want none when nothing changed", len(fake.prompts))
+	}
+}
@@ -0,0 +1,193 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+const chatInstructions = `You will be asked questions about these issues. Answer from the issues above,
+citing them as #number, and say so when they don't hold the answer. Be concise.`
+
+// issueRef matches an issue reference such as #123 in a reply.
+var issueRef = regexp.MustCompile(`#(\d+)\b`)
+
+// Chat is a conversation about a repository's issues. The issues are
+// fetched once and sent at the start of every request, followed by as many
+// earlier exchanges as fit in the input budget.
+type Chat struct {
+	opts    Options
+	subj    subject
+	issues  []Issue
+	context string
+	turns   []turn
+}
+
+// turn is one question and its answer.
+type turn struct {
+	question, answer string
+	at               time.Time
+}
+
+// NewChat fetches the issues of opts.Source, and their comments if
+// opts.IncludeComments is set, and prepares a conversation about them. The
+// issues get at most two thirds of the input budget, leaving the rest for
+// the conversation.
+func NewChat(ctx context.Context, opts Options) (*Chat, error) {
+	c := &Chat{opts: opts, subj: subject{Source: opts.Source.Name(), Repo: opts.Source.Repo(), State: opts.Filter.State}}
+	logf("Fetching issues from %s...\n", c.subj.Repo)
+
+	issues, err := opts.Source.FetchIssues(ctx, opts.Filter, opts.MaxIssues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issues: %w", err)
+	}
+	if len(issues) == 0 {
+		return nil, fmt.Errorf("no matching issues found in %s", c.subj.Repo)
+	}
+	c.issues = issues
+
+	if opts.IncludeComments {
+		c.subj.Comments, err = fetchComments(ctx, opts.Source, issues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch comments: %w", err)
+		}
+	}
+
+	contextOpts := opts
+	contextOpts.InputBudget = inputBudget(opts) * 2 / 3
+	c.context, err = fitPrompt(ctx, contextOpts, c.subj, issues, chatInstructions)
+	if err != nil {
+		return nil, err
+	}
+	logf("Loaded %d issues from %s.\n", len(issues), c.subj.Repo)
+	return c, nil
+}
+
+// Repo is the repository the conversation is about.
+func (c *Chat) Repo() string {
+	return c.subj.Repo
+}
+
+// Ask sends question along with the conversation so far, calling onText
+// with the answer as it streams in. The exchange is kept only if it
+// succeeds, so a failed question can simply be asked again.
+func (c *Chat) Ask(ctx context.Context, question string, onText func(string)) (string, error) {
+	turns := append(c.turns, turn{question: question})
+	budget := inputBudget(c.opts)
+	dropped := 0
+	for len(turns) > 1 && estimateTokens(c.context)+turnTokens(turns) > budget {
+		turns = turns[1:]
+		dropped++
+	}
+	if dropped > 0 {
+		logf("Leaving out the %d oldest exchanges to stay within the input budget.\n", dropped)
+	}
+
+	answer, err := converse(ctx, c.opts, c.messages(turns), true, onText)
+	if err != nil {
+		return "", err
+	}
+	c.turns = append(c.turns, turn{question: question, answer: answer, at: time.Now()})
+	return answer, nil
+}
+
+// messages lays out turns as a conversation, the last of which awaits its
+// answer. The issues lead the first question; with opts.Params.PromptCache set they
+// are cached, so each question after the first reads them from the cache.
+func (c *Chat) messages(turns []turn) []claude.Message {
+	var msgs []claude.Message
+	for i, t := range turns {
+		switch {
+		case i > 0:
+			msgs = append(msgs, claude.Message{Role: "user", Content: t.question})
+		case c.opts.Params.PromptCache:
+			msgs = append(msgs, claude.CachedMessage("user", c.context+"\n\n", t.question))
+		default:
+			msgs = append(msgs, claude.Message{Role: "user", Content: c.context + "\n\n" + t.question})
+		}
+		if i < len(turns)-1 {
+			msgs = append(msgs, claude.Message{Role: "assistant", Content: t.answer})
+		}
+	}
+	return msgs
+}
+
+func turnTokens(turns []turn) int {
+	n := 0
+	for _, t := range turns {
+		n += estimateTokens(t.question) + estimateTokens(t.answer)
+	}
+	return n
+}
+
+// Reset forgets the conversation, keeping the issues.
+func (c *Chat) Reset() {
+	c.turns = nil
+}
+
+// Referenced returns the issues cited in the last answer, in the order
+// they were first cited.
+func (c *Chat) Referenced() []Issue {
+	if len(c.turns) == 0 {
+		return nil
+	}
+	var refs []Issue
+	for _, m := range issueRef.FindAllStringSubmatch(c.turns[len(c.turns)-1].answer, -1) {
+		n, _ := strconv.Atoi(m[1])
+		issue, ok := c.Issue(n)
+		if ok && !slices.ContainsFunc(refs, func(i Issue) bool { return i.Number == n }) {
+			refs = append(refs, issue)
+		}
+	}
+	return refs
+}
+
+// Issue returns the numbered issue, if it is one of the loaded issues.
+func (c *Chat) Issue(number int) (Issue, bool) {
+	i := slices.IndexFunc(c.issues, func(i Issue) bool { return i.Number == number })
+	if i < 0 {
+		return Issue{}, false
+	}
+	return c.issues[i], true
+}
+
+// WriteIssue writes an issue in full, with its comments if they were
+// fetched.
+func (c *Chat) WriteIssue(w io.Writer, issue Issue) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#%d %s\n", issue.Number, issue.Title)
+	fmt.Fprintf(&b, "%s, opened by %s on %s, %d comments\n", issue.State, issue.Author, issue.CreatedAt.Format("2006-01-02"), issue.Comments)
+	if len(issue.Labels) > 0 {
+		fmt.Fprintf(&b, "Labels: %s\n", strings.Join(issue.Labels, ", "))
+	}
+	if issue.URL != "" {
+		fmt.Fprintf(&b, "%s\n", issue.URL)
+	}
+	if body := strings.TrimSpace(issue.Body); body != "" {
+		fmt.Fprintf(&b, "\n%s\n", body)
+	}
+	for _, comment := range c.subj.Comments[issue.Number] {
+		fmt.Fprintf(&b, "\n%s on %s:\n%s\n", comment.Author, comment.CreatedAt.Format("2006-01-02"), strings.TrimSpace(comment.Body))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteTranscript writes the conversation as Markdown.
+func (c *Chat) WriteTranscript(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Questions about %s\n\n", c.subj.describe())
+	fmt.Fprintf(&b, "%d issues from %s, answered by %s.\n", len(c.issues), c.subj.Repo, c.opts.Model)
+	for _, t := range c.turns {
+		fmt.Fprintf(&b, "\n## %s\n\n_%s_\n\n%s\n", strings.TrimSpace(t.question), t.at.Format("2006-01-02 15:04"), strings.TrimSpace(t.answer))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
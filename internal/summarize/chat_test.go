@@ -0,0 +1,117 @@
+package summarize
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func newTestChat(t *testing.T, provider Provider, budget int) *Chat {
+	t.Helper()
+	src := &fakeSource{issues: []Issue{testIssue(1, "crash on start"), testIssue(2, "slow search"), testIssue(3, "typo")}}
+	c, err := NewChat(context.Background(), Options{Source: src, Provider: provider, Model: "m", InputBudget: budget})
+	if err != nil {
+		t.Fatalf("NewChat() error: %v", err)
+	}
+	return c
+}
+
+func TestChat_Conversation(t *testing.T) {
+	provider := &fakeProvider{replies: []string{"#1 and #9 block it.", "Yes, #2 too."}}
+	c := newTestChat(t, provider, 0)
+
+	if _, err := c.Ask(context.Background(), "What blocks 2.0?", nil); err != nil {
+		t.Fatal(err)
+	}
+	refs := c.Referenced()
+	if len(refs) != 1 || refs[0].Number != 1 {
+		t.Errorf("Referenced() = %v, want only #1, the loaded issue", refs)
+	}
+
+	if _, err := c.Ask(context.Background(), "Anything else?", nil); err != nil {
+		t.Fatal(err)
+	}
+	msgs := provider.requests[1].Messages
+	if len(msgs) != 3 || msgs[1].Role != "assistant" || msgs[1].Content != "#1 and #9 block it." || msgs[2].Content != "Anything else?" {
+		t.Fatalf("second request should carry the conversation so far, got %+v", msgs)
+	}
+	if !strings.Contains(msgs[0].Content, "--- Issue #3 ---") || !strings.HasSuffix(msgs[0].Content, "What blocks 2.0?") {
+		t.Errorf("first message should hold the issues and the first question:\n%s", msgs[0].Content)
+	}
+
+	var transcript strings.Builder
+	if err := c.WriteTranscript(&transcript); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## What blocks 2.0?", "Yes, #2 too.", "3 issues from o/r"} {
+		if !strings.Contains(transcript.String(), want) {
+			t.Errorf("transcript missing %q:\n%s", want, transcript.String())
+		}
+	}
+
+	c.Reset()
+	if _, err := c.Ask(context.Background(), "Start over", nil); err != nil {
+		t.Fatal(err)
+	}
+	if msgs := provider.requests[2].Messages; len(msgs) != 1 || !strings.HasSuffix(msgs[0].Content, "Start over") {
+		t.Errorf("after Reset the request should hold only the new question, got %d messages", len(msgs))
+	}
+}
+
+func TestChat_DropsOldestTurns(t *testing.T) {
+	long := strings.Repeat("detail ", 1000)
+	provider := &fakeProvider{replies: []string{long, long, long}}
+	c := newTestChat(t, provider, 3000)
+
+	for _, q := range []string{"one", "two", "three"} {
+		if _, err := c.Ask(context.Background(), q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msgs := provider.requests[2].Messages
+	if len(msgs) >= 5 {
+		t.Fatalf("third request has %d messages, want the oldest exchanges left out", len(msgs))
+	}
+	if !strings.Contains(msgs[0].Content, "--- Issue #1 ---") {
+		t.Error("the issues should still lead the conversation")
+	}
+}
+
+func TestChat_PromptCache(t *testing.T) {
+	provider := &fakeProvider{}
+	src := &fakeSource{issues: []Issue{testIssue(1, "crash on start")}}
+	c, err := NewChat(context.Background(), Options{Source: src, Provider: provider, Model: "m", Params: Params{PromptCache: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{"one", "two"} {
+		if _, err := c.Ask(context.Background(), q, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first := provider.requests[1].Messages[0]
+	if !first.Cached() || !strings.Contains(first.Blocks[0].Text, "--- Issue #1 ---") || first.Blocks[1].Text != "one" {
+		t.Errorf("first message = %+v, want the issues cached ahead of the first question", first)
+	}
+	if provider.requests[0].Messages[0].Blocks[0].Text != first.Blocks[0].Text {
+		t.Error("the cached issues should be the same in every request")
+	}
+}
+
+func TestChat_WriteIssue(t *testing.T) {
+	c := newTestChat(t, &fakeProvider{}, 0)
+	issue, ok := c.Issue(2)
+	if !ok {
+		t.Fatal("Issue(2) not found")
+	}
+	if _, ok := c.Issue(42); ok {
+		t.Error("Issue(42) should not be found")
+	}
+	var b strings.Builder
+	if err := c.WriteIssue(&b, issue); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "#2 Issue\n") || !strings.Contains(b.String(), "slow search") {
+		t.Errorf("WriteIssue() = %q", b.String())
+	}
+}
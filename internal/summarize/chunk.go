@@ -0,0 +1,174 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+// promptOverhead reserves room in every batch for the header and the
+// instructions that wrap the issue listing.
+const promptOverhead = 1000
+
+const batchInstructions = `Summarize this batch so it can be merged with summaries of the other batches later:
+1. Main themes/categories, each with a count and the issue numbers involved
+2. Notable patterns (e.g., recurring problems, areas needing attention)
+3. Up to 5 of the most important issues in this batch, by number, and why they stand out
+
+Be concise. Do not write an introduction or conclusion.`
+
+const combineInstructions = `Merge these partial summaries into a single partial summary in the same format.
+Combine overlapping themes, add up their counts, and keep the issue numbers.
+Keep at most 10 important issues overall.`
+
+// estimateTokens approximates the token count of s using the common
+// four-characters-per-token rule of thumb.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// requestTokens estimates the input tokens of req: its system prompt,
+// messages and tool definitions.
+func requestTokens(req claude.Request) int {
+	n := estimateTokens(req.System)
+	for _, m := range req.Messages {
+		n += estimateTokens(m.Text())
+		for _, b := range m.Blocks {
+			n += estimateTokens(string(b.Input) + b.Content)
+		}
+	}
+	for _, t := range req.Tools {
+		n += estimateTokens(t.Name + t.Description + string(t.InputSchema))
+	}
+	return n
+}
+
+func issueTokens(issue Issue, comments []Comment, d detail) int {
+	var b strings.Builder
+	writeIssue(&b, issue, comments, d)
+	return estimateTokens(b.String())
+}
+
+// splitBatches groups issues, in order, into batches whose rendered size
+// stays within budget tokens. An issue larger than the budget on its own
+// still gets a batch to itself rather than being dropped.
+func splitBatches(subj subject, issues []Issue, budget int) [][]Issue {
+	budget -= promptOverhead
+
+	var batches [][]Issue
+	var current []Issue
+	used := 0
+	for _, issue := range issues {
+		n := issueTokens(issue, subj.Comments[issue.Number], subj.detail(issue.Number))
+		if len(current) > 0 && used+n > budget {
+			batches = append(batches, current)
+			current, used = nil, 0
+		}
+		current = append(current, issue)
+		used += n
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// condenseBatches summarizes each batch separately, merging the partial
+// summaries further if needed, and returns the prompt for the final reduce
+// pass over them.
+func condenseBatches(ctx context.Context, opts Options, subj subject, total int, batches [][]Issue, instructions string) (string, error) {
+	partials := make([]string, len(batches))
+	for i, batch := range batches {
+		logf("Summarizing batch %d/%d (%d issues)...\n", i+1, len(batches), len(batch))
+		partial, err := complete(ctx, opts, buildBatchPrompt(subj, total, i, len(batches), batch))
+		if err != nil {
+			return "", fmt.Errorf("batch %d/%d: %w", i+1, len(batches), err)
+		}
+		partials[i] = partial
+	}
+
+	budget := inputBudget(opts) - promptOverhead
+	for estimateTokens(strings.Join(partials, "\n\n")) > budget {
+		groups := groupPartials(partials, budget)
+		if len(groups) == len(partials) {
+			return "", fmt.Errorf("partial summaries are too large to combine")
+		}
+		logf("Combining %d partial summaries into %d...\n", len(partials), len(groups))
+		combined := make([]string, len(groups))
+		for i, group := range groups {
+			c, err := complete(ctx, opts, buildCombinePrompt(subj, group))
+			if err != nil {
+				return "", fmt.Errorf("combining partial summaries: %w", err)
+			}
+			combined[i] = c
+		}
+		partials = combined
+	}
+
+	logf("Combining batch summaries...\n")
+	return buildReducePrompt(subj, total, partials, instructions), nil
+}
+
+func groupPartials(partials []string, budget int) [][]string {
+	var groups [][]string
+	var current []string
+	used := 0
+	for _, p := range partials {
+		n := estimateTokens(p)
+		if len(current) > 0 && used+n > budget {
+			groups = append(groups, current)
+			current, used = nil, 0
+		}
+		current = append(current, p)
+		used += n
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+func buildBatchPrompt(subj subject, total, index, count int, issues []Issue) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "These are the %s of the repository %s.\n", subj.describe(), subj.Repo)
+	fmt.Fprintf(&b, "There are %d %s, split into %d batches. This is batch %d, with %d issues:\n\n", total, subj.issues(), count, index+1, len(issues))
+
+	for _, issue := range issues {
+		writeIssue(&b, issue, subj.Comments[issue.Number], subj.detail(issue.Number))
+	}
+
+	b.WriteString(batchInstructions)
+
+	return b.String()
+}
+
+func buildCombinePrompt(subj subject, partials []string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "These are partial summaries of %s for the repository %s.\n\n", subj.describe(), subj.Repo)
+	writePartials(&b, partials)
+	b.WriteString(combineInstructions)
+
+	return b.String()
+}
+
+func buildReducePrompt(subj subject, total int, partials []string, instructions string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "These are the %s of the repository %s.\n", subj.describe(), subj.Repo)
+	fmt.Fprintf(&b, "There are %d %s, too many to review at once, so they were summarized in %d parts:\n\n", total, subj.issues(), len(partials))
+	writePartials(&b, partials)
+	fmt.Fprintf(&b, "Treat the parts together as covering all %d issues.\n\n", total)
+	b.WriteString(instructions)
+
+	return b.String()
+}
+
+func writePartials(b *strings.Builder, partials []string) {
+	for i, p := range partials {
+		fmt.Fprintf(b, "--- Part %d ---\n%s\n\n", i+1, strings.TrimSpace(p))
+	}
+}
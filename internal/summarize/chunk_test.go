@@ -0,0 +1,142 @@
+package summarize
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func testIssue(number int, body string) Issue {
+	return Issue{
+		Number:    number,
+		Title:     "Issue",
+		Author:    "a",
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Body:      body,
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if got := estimateTokens(""); got != 0 {
+		t.Errorf("estimateTokens('') = %d, want 0", got)
+	}
+	if got := estimateTokens("abcdefgh"); got != 2 {
+		t.Errorf("estimateTokens(8 chars) = %d, want 2", got)
+	}
+	if got := estimateTokens("abcde"); got != 2 {
+		t.Errorf("estimateTokens(5 chars) = %d, want 2", got)
+	}
+}
+
+func TestSplitBatches_FitsInOne(t *testing.T) {
+	issues := []Issue{testIssue(1, "a"), testIssue(2, "b"), testIssue(3, "c")}
+
+	batches := splitBatches(subject{}, issues, 150000)
+
+	if len(batches) != 1 {
+		t.Fatalf("got %d batches, want 1", len(batches))
+	}
+	if len(batches[0]) != 3 {
+		t.Errorf("batch has %d issues, want 3", len(batches[0]))
+	}
+}
+
+func TestSplitBatches_SplitsByBudget(t *testing.T) {
+	var issues []Issue
+	for i := 1; i <= 10; i++ {
+		issues = append(issues, testIssue(i, strings.Repeat("x", 400)))
+	}
+	per := issueTokens(issues[0], nil, defaultDetail)
+
+	batches := splitBatches(subject{}, issues, promptOverhead+3*per)
+
+	if len(batches) != 4 {
+		t.Fatalf("got %d batches, want 4", len(batches))
+	}
+	total := 0
+	next := 1
+	for _, batch := range batches {
+		if len(batch) > 3 {
+			t.Errorf("batch has %d issues, want at most 3", len(batch))
+		}
+		for _, issue := range batch {
+			if issue.Number != next {
+				t.Errorf("issue #%d out of order, want #%d", issue.Number, next)
+			}
+			next++
+		}
+		total += len(batch)
+	}
+	if total != 10 {
+		t.Errorf("batches hold %d issues, want 10", total)
+	}
+}
+
+func TestSplitBatches_OversizedIssue(t *testing.T) {
+	issues := []Issue{testIssue(1, "a"), testIssue(2, strings.Repeat("x", 400)), testIssue(3, "c")}
+
+	batches := splitBatches(subject{}, issues, promptOverhead+issueTokens(issues[0], nil, defaultDetail)+1)
+
+	if len(batches) != 3 {
+		t.Fatalf("got %d batches, want 3", len(batches))
+	}
+	if batches[1][0].Number != 2 {
+		t.Errorf("oversized issue should get its own batch, got %v", batches[1])
+	}
+}
+
+func TestSplitBatches_Empty(t *testing.T) {
+	if got := splitBatches(subject{}, nil, 150000); len(got) != 0 {
+		t.Errorf("got %d batches for no issues, want 0", len(got))
+	}
+}
+
+func TestGroupPartials(t *testing.T) {
+	partials := []string{strings.Repeat("x", 40), strings.Repeat("y", 40), strings.Repeat("z", 40)}
+
+	groups := groupPartials(partials, 20)
+
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+	if len(groups[0]) != 2 || len(groups[1]) != 1 {
+		t.Errorf("unexpected grouping: %d + %d", len(groups[0]), len(groups[1]))
+	}
+}
+
+func TestBuildBatchPrompt(t *testing.T) {
+	issues := []Issue{testIssue(7, "body")}
+
+	prompt := buildBatchPrompt(subject{Repo: "o/r"}, 250, 1, 3, issues)
+
+	checks := []string{
+		"o/r",
+		"250 open issues",
+		"3 batches",
+		"batch 2, with 1 issues",
+		"Issue #7",
+	}
+	for _, want := range checks {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestBuildReducePrompt(t *testing.T) {
+	prompt := buildReducePrompt(subject{Repo: "o/r"}, 500, []string{"first partial", "second partial"}, summaryInstructions)
+
+	checks := []string{
+		"o/r",
+		"500 open issues",
+		"2 parts",
+		"--- Part 1 ---\nfirst partial",
+		"--- Part 2 ---\nsecond partial",
+		"top 5 most important issues",
+	}
+	for _, want := range checks {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
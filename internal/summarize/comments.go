@@ -0,0 +1,96 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mrphil/gitissuesum/internal/truncate"
+)
+
+const (
+	// maxDigestChars caps the comment digest for a single issue where the
+	// budgeter hasn't set a limit, and maxCommentChars caps each comment
+	// within it.
+	maxDigestChars  = 1500
+	maxCommentChars = 300
+)
+
+func fetchComments(ctx context.Context, src Source, issues []Issue) (map[int][]Comment, error) {
+	var numbers []int
+	for _, issue := range issues {
+		if issue.Comments > 0 {
+			numbers = append(numbers, issue.Number)
+		}
+	}
+	if len(numbers) == 0 {
+		return nil, nil
+	}
+
+	logf("Fetching comments for %d issues...\n", len(numbers))
+	return src.FetchComments(ctx, numbers)
+}
+
+// writeDigest renders as many comments as fit in maxChars. Maintainer
+// comments are picked first, then the most recent ones, and the chosen
+// comments are shown in their original order.
+func writeDigest(b *strings.Builder, comments []Comment, maxChars int) {
+	if len(comments) == 0 {
+		return
+	}
+
+	lines := digestLines(comments)
+
+	order := make([]int, 0, len(comments))
+	for i := len(comments) - 1; i >= 0; i-- {
+		if comments[i].Maintainer {
+			order = append(order, i)
+		}
+	}
+	for i := len(comments) - 1; i >= 0; i-- {
+		if !comments[i].Maintainer {
+			order = append(order, i)
+		}
+	}
+
+	var chosen []int
+	used := 0
+	for _, i := range order {
+		if used+len(lines[i]) > maxChars {
+			continue
+		}
+		chosen = append(chosen, i)
+		used += len(lines[i])
+	}
+	slices.Sort(chosen)
+
+	fmt.Fprintf(b, "Discussion (%d comments):\n", len(comments))
+	for _, i := range chosen {
+		b.WriteString(lines[i])
+	}
+	if omitted := len(comments) - len(chosen); omitted > 0 {
+		fmt.Fprintf(b, "  (%d more comments not shown)\n", omitted)
+	}
+}
+
+func digestLines(comments []Comment) []string {
+	lines := make([]string, len(comments))
+	for i, c := range comments {
+		who := c.Author
+		if c.Maintainer {
+			who += " (maintainer)"
+		}
+		lines[i] = fmt.Sprintf("  - %s: %s\n", who, truncate.String(strings.Join(strings.Fields(c.Body), " "), maxCommentChars))
+	}
+	return lines
+}
+
+// digestLen is how long the digest of comments would be with no limit.
+func digestLen(comments []Comment) int {
+	n := 0
+	for _, line := range digestLines(comments) {
+		n += len(line)
+	}
+	return n
+}
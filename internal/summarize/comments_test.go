@@ -0,0 +1,93 @@
+package summarize
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testComment(author string, maintainer bool, body string) Comment {
+	return Comment{Author: author, Maintainer: maintainer, Body: body}
+}
+
+func TestWriteDigest_Empty(t *testing.T) {
+	var b strings.Builder
+	writeDigest(&b, nil, maxDigestChars)
+	if b.Len() != 0 {
+		t.Errorf("digest for no comments = %q, want empty", b.String())
+	}
+}
+
+func TestWriteDigest_AllFit(t *testing.T) {
+	comments := []Comment{
+		testComment("alice", false, "Same here on\nLinux."),
+		testComment("bob", true, "Fixed in main."),
+	}
+
+	var b strings.Builder
+	writeDigest(&b, comments, maxDigestChars)
+	got := b.String()
+
+	want := "Discussion (2 comments):\n  - alice: Same here on Linux.\n  - bob (maintainer): Fixed in main.\n"
+	if got != want {
+		t.Errorf("digest = %q, want %q", got, want)
+	}
+}
+
+func TestWriteDigest_TruncatesComment(t *testing.T) {
+	var b strings.Builder
+	writeDigest(&b, []Comment{testComment("a", false, strings.Repeat("x", maxCommentChars+50))}, maxDigestChars)
+
+	if strings.Contains(b.String(), strings.Repeat("x", maxCommentChars+1)) {
+		t.Error("long comment should be truncated")
+	}
+}
+
+func TestWriteDigest_Budget(t *testing.T) {
+	var comments []Comment
+	for i := range 20 {
+		comments = append(comments, testComment(fmt.Sprintf("user%d", i), false, strings.Repeat("x", 200)))
+	}
+	comments[0] = testComment("owner", true, "We will not fix this.")
+
+	var b strings.Builder
+	writeDigest(&b, comments, maxDigestChars)
+	got := b.String()
+
+	if len(got) > maxDigestChars+100 {
+		t.Errorf("digest is %d chars, want about %d at most", len(got), maxDigestChars)
+	}
+	if !strings.Contains(got, "owner (maintainer): We will not fix this.") {
+		t.Error("maintainer comment should be kept even though it is the oldest")
+	}
+	if !strings.Contains(got, "user19:") {
+		t.Error("most recent comment should be kept")
+	}
+	if strings.Contains(got, "user1:") {
+		t.Error("old non-maintainer comments should be dropped first")
+	}
+	if !strings.Contains(got, "more comments not shown") {
+		t.Error("digest should say how many comments were omitted")
+	}
+}
+
+func TestBuildPrompt_Comments(t *testing.T) {
+	issues := []Issue{
+		{Number: 5, Title: "Crash", Author: "a", Comments: 1, CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Number: 6, Title: "Other", Author: "b", CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	subj := subject{
+		Repo:     "o/r",
+		Comments: map[int][]Comment{5: {testComment("c", false, "Workaround: restart")}},
+	}
+
+	prompt := buildPrompt(subj, issues, summaryInstructions)
+
+	if !strings.Contains(prompt, "Discussion (1 comments):\n  - c: Workaround: restart") {
+		t.Errorf("prompt missing comment digest, got:\n%s", prompt)
+	}
+	if strings.Count(prompt, "Discussion") != 1 {
+		t.Error("only issues with comments should get a digest")
+	}
+}
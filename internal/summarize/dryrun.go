@@ -0,0 +1,134 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+// DryRun is a Provider that records requests instead of sending them. Each
+// gets a placeholder reply, so requests whose replies feed later prompts,
+// such as batch summaries, show where that text would go. Runs stop short
+// of the output once the final request is recorded.
+type DryRun struct {
+	// Counter, if set, answers CountTokens, since counting doesn't run the
+	// model.
+	Counter TokenCounter
+
+	mu       sync.Mutex
+	requests []claude.Request
+}
+
+func (d *DryRun) Send(ctx context.Context, req claude.Request) (*claude.Response, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, req)
+	text := fmt.Sprintf("(dry run: the reply to request %d would go here)", len(d.requests))
+	return &claude.Response{Content: []claude.ContentBlock{{Type: "text", Text: text}}}, nil
+}
+
+// Stream records req like Send. onText isn't called, so nothing is printed
+// as if it were a summary.
+func (d *DryRun) Stream(ctx context.Context, req claude.Request, onText func(string)) (*claude.Response, error) {
+	return d.Send(ctx, req)
+}
+
+func (d *DryRun) CountTokens(ctx context.Context, req claude.Request) (int, error) {
+	if d.Counter == nil {
+		return 0, errors.ErrUnsupported
+	}
+	return d.Counter.CountTokens(ctx, req)
+}
+
+// Requests returns the recorded requests in the order they were made.
+func (d *DryRun) Requests() []claude.Request {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]claude.Request(nil), d.requests...)
+}
+
+// WriteText writes each recorded request for people to read: its settings,
+// tools, estimated size and the full content of its messages.
+func (d *DryRun) WriteText(w io.Writer) error {
+	requests := d.Requests()
+	if len(requests) == 0 {
+		_, err := fmt.Fprintln(w, "No requests would be sent.")
+		return err
+	}
+	for i, req := range requests {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "=== Request %d of %d ===\n", i+1, len(requests))
+		fmt.Fprintf(w, "Model: %s\n", req.Model)
+		fmt.Fprintf(w, "Max tokens: %d\n", req.MaxTokens)
+		if req.Temperature != nil {
+			fmt.Fprintf(w, "Temperature: %v\n", *req.Temperature)
+		}
+		if req.TopP != nil {
+			fmt.Fprintf(w, "Top p: %v\n", *req.TopP)
+		}
+		if len(req.StopSequences) > 0 {
+			fmt.Fprintf(w, "Stop sequences: %q\n", req.StopSequences)
+		}
+		if req.Metadata != nil {
+			fmt.Fprintf(w, "User ID: %s\n", req.Metadata.UserID)
+		}
+		for _, t := range req.Tools {
+			fmt.Fprintf(w, "Tool: %s: %s\n", t.Name, t.Description)
+		}
+		fmt.Fprintf(w, "Estimated input tokens: %s\n", thousands(requestTokens(req)))
+		if req.System != "" {
+			fmt.Fprintf(w, "\n--- system ---\n%s\n", req.System)
+		}
+		for _, m := range req.Messages {
+			if _, err := fmt.Fprintf(w, "\n--- %s ---\n", m.Role); err != nil {
+				return err
+			}
+			if m.Blocks == nil {
+				fmt.Fprintf(w, "%s\n", m.Content)
+			}
+			for _, b := range m.Blocks {
+				switch b.Type {
+				case "tool_use":
+					fmt.Fprintf(w, "[tool_use %s, id %s] %s\n", b.Name, b.ID, b.Input)
+				case "tool_result":
+					status := "tool_result"
+					if b.IsError {
+						status += " (error)"
+					}
+					fmt.Fprintf(w, "[%s for %s] %s\n", status, b.ToolUseID, b.Content)
+				default:
+					fmt.Fprintf(w, "%s\n", b.Text)
+				}
+				if b.CacheControl != nil {
+					fmt.Fprintf(w, "--- (cached up to here) ---\n")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// WriteRequests writes the recorded requests as JSON Lines, one Messages API
+// request body per line, so they can be replayed as they are.
+func (d *DryRun) WriteRequests(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, req := range d.Requests() {
+		if err := enc.Encode(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isDryRun(opts Options) bool {
+	_, ok := opts.Provider.(*DryRun)
+	return ok
+}
@@ -0,0 +1,113 @@
+package summarize
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+func TestRun_DryRun(t *testing.T) {
+	for _, output := range []string{OutputText, OutputJSON} {
+		t.Run(output, func(t *testing.T) {
+			recorder := &DryRun{}
+			src := &fakeSource{issues: []Issue{testIssue(1, "the body"), testIssue(2, "another")}}
+			snap, err := Run(context.Background(), Options{Source: src, Provider: recorder, Model: "m", Output: output})
+			if err != nil {
+				t.Fatalf("Run() error: %v", err)
+			}
+			if snap.Summary != "" {
+				t.Errorf("summary = %q, want none in a dry run", snap.Summary)
+			}
+			requests := recorder.Requests()
+			if len(requests) != 1 {
+				t.Fatalf("recorded %d requests, want 1", len(requests))
+			}
+			if prompt := requests[0].Messages[0].Content; !strings.Contains(prompt, "--- Issue #2 ---") {
+				t.Errorf("prompt missing issue #2:\n%s", prompt)
+			}
+		})
+	}
+}
+
+func TestRun_DryRunBatches(t *testing.T) {
+	var issues []Issue
+	for i := 1; i <= 40; i++ {
+		issues = append(issues, testIssue(i, strings.Repeat("word ", 100)))
+	}
+	recorder := &DryRun{}
+	opts := Options{Source: &fakeSource{issues: issues}, Provider: recorder, Model: "m", InputBudget: 2000}
+	if _, err := Run(context.Background(), opts); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	requests := recorder.Requests()
+	if len(requests) < 3 {
+		t.Fatalf("recorded %d requests, want the batches and the reduce pass", len(requests))
+	}
+	last := requests[len(requests)-1].Messages[0].Content
+	if !strings.Contains(last, "(dry run: the reply to request 1 would go here)") {
+		t.Errorf("reduce prompt should show where the batch summaries go:\n%s", last)
+	}
+}
+
+func TestDryRun_Write(t *testing.T) {
+	recorder := &DryRun{}
+	recorder.Send(context.Background(), claude.NewRequest("m", "first prompt"))
+	recorder.Send(context.Background(), claude.NewRequest("m", "second <prompt>"))
+
+	var text strings.Builder
+	if err := recorder.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"=== Request 2 of 2 ===", "Max tokens: 4096", "Estimated input tokens: 3", "--- user ---\nfirst prompt"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var jsonl strings.Builder
+	if err := recorder.WriteRequests(&jsonl); err != nil {
+		t.Fatal(err)
+	}
+	scanner := bufio.NewScanner(strings.NewReader(jsonl.String()))
+	var got []claude.Request
+	for scanner.Scan() {
+		var req claude.Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			t.Fatalf("line %q is not a request: %v", scanner.Text(), err)
+		}
+		got = append(got, req)
+	}
+	if len(got) != 2 || got[1].Messages[0].Content != "second <prompt>" || got[1].MaxTokens != 4096 {
+		t.Errorf("requests = %+v", got)
+	}
+}
+
+func TestDryRun_WriteTextTools(t *testing.T) {
+	req := claude.NewChatRequest("m", []claude.Message{
+		{Role: "user", Content: "triage these"},
+		{Role: "assistant", Blocks: []claude.ContentBlock{toolUse("tu_1", ActionPriority, `{"issue":1,"level":"high"}`)}},
+		{Role: "user", Blocks: []claude.ContentBlock{claude.ToolResult("tu_1", "issue #1 is not among the issues given", true)}},
+	})
+	req.Tools = triageTools
+	recorder := &DryRun{}
+	recorder.Send(context.Background(), req)
+
+	var text strings.Builder
+	if err := recorder.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Tool: set_priority: Set an issue's priority.",
+		`[tool_use set_priority, id tu_1] {"issue":1,"level":"high"}`,
+		"[tool_result (error) for tu_1] issue #1 is not among the issues given",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text missing %q:\n%s", want, text.String())
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/truncate"
+)
+
+const rollupInstructions = `Please provide a cross-repository roll-up:
+1. An overview across all repositories (2-3 sentences)
+2. Themes shared by several repositories, naming the repositories involved
+3. Repositories that stand out, and why
+4. The most important issues overall, as owner/repo#number, and why
+
+Be concise and actionable.`
+
+const rollupCombineInstructions = `Merge these repository summaries into a single summary in the same format, to
+be rolled up with the others later. Combine shared themes and name the
+repositories each involves, and keep the most important issues as
+owner/repo#number.`
+
+// MultiSummary is the --output json form of a multi-repository run.
+type MultiSummary struct {
+	Repositories []*Summary    `json:"repositories"`
+	Rollup       string        `json:"rollup"`
+	Failed       []RepoFailure `json:"failed,omitempty"`
+	Usage        *UsageReport  `json:"usage,omitempty"`
+}
+
+type RepoFailure struct {
+	Repository string `json:"repository"`
+	Error      string `json:"error"`
+}
+
+// repoResult is one repository's outcome in RunAll.
+type repoResult struct {
+	subj    subject
+	snap    *Snapshot
+	summary *Summary
+	text    string
+	err     error
+}
+
+// RunAll summarizes each source on its own, working on at most concurrency
+// of them at a time, then writes a roll-up of the summaries that highlights
+// what the repositories have in common. opts.Source is replaced by each
+// source in turn. A repository that fails is reported and left out of the
+// roll-up; RunAll still returns an error for it once everything else is
+// written. Snapshots are returned for the repositories that succeeded.
+func RunAll(ctx context.Context, opts Options, sources []Source, concurrency int) ([]*Snapshot, error) {
+	logf("Summarizing %d repositories, %d at a time...\n", len(sources), max(concurrency, 1))
+	results := make([]repoResult, len(sources))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(concurrency, 1))
+	for i, src := range sources {
+		wg.Add(1)
+		go func(i int, src Source) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = repoResult{subj: subject{Repo: src.Repo()}, err: ctx.Err()}
+				return
+			}
+			defer func() { <-sem }()
+
+			o := opts
+			o.Source = src
+			results[i] = summarizeRepo(ctx, o)
+		}(i, src)
+	}
+	wg.Wait()
+
+	var snaps []*Snapshot
+	var errs []error
+	multi := &MultiSummary{Repositories: []*Summary{}}
+	var parts []string
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.subj.Repo, r.err))
+			multi.Failed = append(multi.Failed, RepoFailure{Repository: r.subj.Repo, Error: r.err.Error()})
+			continue
+		}
+		snaps = append(snaps, r.snap)
+		switch {
+		case isDryRun(opts):
+		case opts.Output == OutputJSON:
+			multi.Repositories = append(multi.Repositories, r.summary)
+		default:
+			fmt.Printf("\n=== %s (%d %s) ===\n\n%s\n", r.subj.Repo, len(r.snap.Issues), r.subj.issues(), strings.TrimSpace(r.text))
+		}
+		if len(r.snap.Issues) > 0 {
+			parts = append(parts, fmt.Sprintf("--- %s (%d %s) ---\n%s\n\n", r.subj.Repo, len(r.snap.Issues), r.subj.issues(), strings.TrimSpace(r.text)))
+		}
+	}
+
+	if len(parts) > 1 {
+		logf("Writing the roll-up across %d repositories...\n", len(parts))
+		prompt, err := fitRollup(ctx, opts, subject{State: opts.Filter.State}, parts)
+		if err != nil {
+			return snaps, fmt.Errorf("failed to get roll-up: %w", err)
+		}
+		if opts.Output == OutputJSON || isDryRun(opts) {
+			text, err := complete(ctx, opts, prompt)
+			if err != nil {
+				return snaps, fmt.Errorf("failed to get roll-up: %w", err)
+			}
+			multi.Rollup = strings.TrimSpace(text)
+		} else {
+			fmt.Printf("\n=== Roll-up across %d repositories ===\n\n", len(parts))
+			_, err := completeStream(ctx, opts, prompt, func(text string) {
+				fmt.Print(text)
+			})
+			fmt.Println()
+			if err != nil {
+				return snaps, fmt.Errorf("failed to get roll-up: %w", err)
+			}
+		}
+	}
+
+	if opts.Output == OutputJSON && !isDryRun(opts) {
+		multi.Usage = usageReport(opts)
+		if err := WriteJSON(multi); err != nil {
+			return snaps, err
+		}
+	}
+	if len(errs) > 0 {
+		return snaps, fmt.Errorf("%d of %d repositories failed: %w", len(errs), len(sources), errors.Join(errs...))
+	}
+	return snaps, nil
+}
+
+// summarizeRepo fetches and summarizes opts.Source without printing, for
+// RunAll to write out in order.
+func summarizeRepo(ctx context.Context, opts Options) repoResult {
+	subj := subject{Source: opts.Source.Name(), Repo: opts.Source.Repo(), State: opts.Filter.State}
+	r := repoResult{subj: subj}
+
+	issues, err := opts.Source.FetchIssues(ctx, opts.Filter, opts.MaxIssues)
+	if err != nil {
+		r.err = fmt.Errorf("failed to fetch issues: %w", err)
+		return r
+	}
+	r.snap = &Snapshot{Source: subj.Source, Repository: subj.Repo, State: opts.Filter.State, TakenAt: time.Now().UTC(), Issues: issues}
+
+	if len(issues) == 0 {
+		r.text = "No matching issues found."
+		r.summary = &Summary{Repository: subj.Repo, Themes: []Theme{}, Patterns: []string{}, TopIssues: []TopIssue{}}
+		return r
+	}
+
+	msg, err := summaryPrompt(ctx, opts, subj, issues)
+	if err != nil {
+		r.err = err
+		return r
+	}
+
+	if opts.Output == OutputJSON && !isDryRun(opts) {
+		r.summary, err = requestSummary(ctx, opts, subj.Repo, msg, issues)
+		if err != nil {
+			r.err = fmt.Errorf("failed to get summary: %w", err)
+			return r
+		}
+		data, err := json.Marshal(r.summary)
+		if err != nil {
+			r.err = err
+			return r
+		}
+		r.text = string(data)
+	} else {
+		r.text, err = completeMessage(ctx, opts, msg)
+		if err != nil {
+			r.err = fmt.Errorf("failed to get summary: %w", err)
+			return r
+		}
+	}
+	r.snap.Summary = r.text
+	return r
+}
+
+// fitRollup builds the roll-up prompt within the input budget. If the
+// repository summaries don't fit together, they are merged in groups first,
+// as condenseBatches does with partial summaries; any that are too large to
+// group are trimmed to an even share of the budget instead.
+func fitRollup(ctx context.Context, opts Options, subj subject, parts []string) (string, error) {
+	repos := len(parts)
+	budget := inputBudget(opts) - promptOverhead - estimateTokens(rollupInstructions)
+	for estimateTokens(strings.Join(parts, "")) > budget {
+		groups := groupPartials(parts, budget)
+		if len(groups) == len(parts) {
+			logf("Trimming %d repository summaries to fit the %d-token input budget.\n", len(parts), inputBudget(opts))
+			trimmed := make([]string, len(parts))
+			for i, p := range parts {
+				trimmed[i] = truncate.String(p, budget*4/len(parts)) + "\n\n"
+			}
+			parts = trimmed
+			break
+		}
+		logf("Combining %d repository summaries into %d to fit the %d-token input budget...\n", len(parts), len(groups), inputBudget(opts))
+		combined := make([]string, len(groups))
+		for i, group := range groups {
+			c, err := complete(ctx, opts, buildRollupCombinePrompt(subj, group))
+			if err != nil {
+				return "", fmt.Errorf("combining repository summaries: %w", err)
+			}
+			combined[i] = fmt.Sprintf("--- Part %d ---\n%s\n\n", i+1, strings.TrimSpace(c))
+		}
+		parts = combined
+	}
+	return buildRollupPrompt(subj, repos, parts), nil
+}
+
+func buildRollupCombinePrompt(subj subject, parts []string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Below are summaries of %s across several repositories.\n\n", subj.describe())
+	for _, p := range parts {
+		b.WriteString(p)
+	}
+	b.WriteString(rollupCombineInstructions)
+
+	return b.String()
+}
+
+// buildRollupPrompt writes the roll-up prompt over parts, which cover repos
+// repositories: one summary each, or fewer if they were combined.
+func buildRollupPrompt(subj subject, repos int, parts []string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Below are summaries of %s across %d repositories.\n", subj.describe(), repos)
+	if len(parts) < repos {
+		fmt.Fprintf(&b, "Each repository was summarized separately, and the summaries were combined into %d parts:\n\n", len(parts))
+	} else {
+		b.WriteString("Each repository was summarized separately:\n\n")
+	}
+	for _, p := range parts {
+		b.WriteString(p)
+	}
+	b.WriteString(rollupInstructions)
+
+	return b.String()
+}
@@ -0,0 +1,122 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+var repoInPrompt = regexp.MustCompile(`of the repository (\S+)\.`)
+
+// echoProvider answers summary requests with the repository's name, so
+// replies don't depend on the order concurrent requests arrive in.
+type echoProvider struct {
+	mu      sync.Mutex
+	prompts []string
+}
+
+func (e *echoProvider) Send(ctx context.Context, req claude.Request) (*claude.Response, error) {
+	prompt := req.Messages[len(req.Messages)-1].Content
+	e.mu.Lock()
+	e.prompts = append(e.prompts, prompt)
+	e.mu.Unlock()
+
+	reply := "roll-up"
+	if m := repoInPrompt.FindStringSubmatch(prompt); m != nil {
+		reply = "summary of " + m[1]
+	}
+	return &claude.Response{Content: []claude.ContentBlock{{Type: "text", Text: reply}}}, nil
+}
+
+func (e *echoProvider) Stream(ctx context.Context, req claude.Request, onText func(string)) (*claude.Response, error) {
+	return e.Send(ctx, req)
+}
+
+func TestRunAll(t *testing.T) {
+	issue := []Issue{testIssue(1, "body")}
+	sources := []Source{
+		&fakeSource{repo: "o/a", issues: issue},
+		&fakeSource{repo: "o/b", issues: issue},
+		&fakeSource{repo: "o/empty"},
+		&fakeSource{repo: "o/broken", err: errors.New("404")},
+	}
+	p := &echoProvider{}
+
+	snaps, err := RunAll(context.Background(), Options{Provider: p, Model: "m"}, sources, 2)
+	if err == nil || !strings.Contains(err.Error(), "o/broken") {
+		t.Errorf("RunAll() error = %v, want one naming the failed repository", err)
+	}
+	if len(snaps) != 3 {
+		t.Fatalf("got %d snapshots, want 3", len(snaps))
+	}
+	if snaps[0].Repository != "o/a" || snaps[0].Summary != "summary of o/a" {
+		t.Errorf("first snapshot = %s %q, want o/a in argument order", snaps[0].Repository, snaps[0].Summary)
+	}
+
+	rollup := p.prompts[len(p.prompts)-1]
+	for _, want := range []string{"across 2 repositories", "--- o/a (1 open issues) ---\nsummary of o/a", "summary of o/b", rollupInstructions} {
+		if !strings.Contains(rollup, want) {
+			t.Errorf("roll-up prompt missing %q", want)
+		}
+	}
+	if strings.Contains(rollup, "o/empty") {
+		t.Error("repositories without issues should be left out of the roll-up")
+	}
+}
+
+func TestRunAll_SingleSuccessSkipsRollup(t *testing.T) {
+	sources := []Source{
+		&fakeSource{repo: "o/a", issues: []Issue{testIssue(1, "body")}},
+		&fakeSource{repo: "o/empty"},
+	}
+	p := &echoProvider{}
+
+	if _, err := RunAll(context.Background(), Options{Provider: p, Model: "m"}, sources, 4); err != nil {
+		t.Fatalf("RunAll() error: %v", err)
+	}
+	if len(p.prompts) != 1 {
+		t.Errorf("sent %d requests, want just the one summary", len(p.prompts))
+	}
+}
+
+func TestFitRollup(t *testing.T) {
+	part := func(repo string, chars int) string {
+		return "--- " + repo + " (1 open issues) ---\n" + strings.Repeat("x", chars) + "\n\n"
+	}
+	// Room for about 500 tokens of summaries.
+	opts := Options{Model: "m", InputBudget: promptOverhead + estimateTokens(rollupInstructions) + 500}
+
+	fake := &fakeProvider{replies: []string{"first half", "second half"}}
+	opts.Provider = fake
+	parts := []string{part("o/a", 800), part("o/b", 800), part("o/c", 800), part("o/d", 800)}
+	prompt, err := fitRollup(context.Background(), opts, subject{}, parts)
+	if err != nil {
+		t.Fatalf("fitRollup() error: %v", err)
+	}
+	if len(fake.prompts) != 2 || !strings.Contains(fake.prompts[0], "--- o/b") || strings.Contains(fake.prompts[0], "--- o/c") {
+		t.Fatalf("sent %d combine requests, want the summaries merged in pairs", len(fake.prompts))
+	}
+	for _, want := range []string{"across 4 repositories", "combined into 2 parts", "first half", "second half", rollupInstructions} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("roll-up prompt missing %q", want)
+		}
+	}
+
+	fake = &fakeProvider{}
+	opts.Provider = fake
+	prompt, err = fitRollup(context.Background(), opts, subject{}, []string{part("o/a", 4000), part("o/b", 4000)})
+	if err != nil {
+		t.Fatalf("fitRollup() error: %v", err)
+	}
+	if len(fake.prompts) != 0 || !strings.Contains(prompt, "--- o/b") {
+		t.Errorf("summaries too large to group should be trimmed without requests, sent %d", len(fake.prompts))
+	}
+	if n := estimateTokens(prompt); n > inputBudget(opts) {
+		t.Errorf("roll-up prompt is %d tokens, over the %d-token budget", n, inputBudget(opts))
+	}
+}
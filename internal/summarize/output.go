@@ -0,0 +1,197 @@
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// maxRepairAttempts is how many times an invalid JSON summary is sent back
+// to the model for correction before giving up.
+const maxRepairAttempts = 2
+
+const jsonSchema = `{
+  "overview": string,         // high-level summary of the issues, 2-3 sentences
+  "themes": [                 // main themes/categories
+    {"name": string, "count": number, "issues": [issue numbers]}
+  ],
+  "patterns": [string],       // notable patterns, e.g. recurring problems
+  "top_issues": [             // the 5 most important issues
+    {"number": issue number, "reason": string}
+  ]
+}`
+
+const jsonInstructions = `Respond with a single JSON object and nothing else (no prose, no code fences), matching this schema:
+` + jsonSchema + `
+
+Only reference issue numbers that appear above. Be concise and actionable.`
+
+type Summary struct {
+	Repository string     `json:"repository"`
+	IssueCount int        `json:"issue_count"`
+	Overview   string     `json:"overview"`
+	Themes     []Theme    `json:"themes"`
+	Patterns   []string   `json:"patterns"`
+	TopIssues  []TopIssue `json:"top_issues"`
+	// Usage is the run's token usage, when it is metered.
+	Usage *UsageReport `json:"usage,omitempty"`
+}
+
+type Theme struct {
+	Name   string `json:"name"`
+	Count  int    `json:"count"`
+	Issues []int  `json:"issues"`
+}
+
+type TopIssue struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Reason string `json:"reason"`
+}
+
+// modelSummary is the part of Summary the model is asked to produce; the
+// remaining fields are filled in from the fetched issues.
+type modelSummary struct {
+	Overview  string   `json:"overview"`
+	Themes    []Theme  `json:"themes"`
+	Patterns  []string `json:"patterns"`
+	TopIssues []struct {
+		Number int    `json:"number"`
+		Reason string `json:"reason"`
+	} `json:"top_issues"`
+}
+
+// requestSummary sends msg and decodes the reply into a Summary, asking the
+// model to repair its output when it is not valid against the schema.
+func requestSummary(ctx context.Context, opts Options, repo string, msg claude.Message, issues []Issue) (*Summary, error) {
+	response, err := completeMessage(ctx, opts, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		summary, parseErr := parseSummary(response, issues)
+		if parseErr == nil {
+			summary.Repository = repo
+			summary.IssueCount = len(issues)
+			return summary, nil
+		}
+		if attempt == maxRepairAttempts {
+			return nil, fmt.Errorf("invalid JSON summary after %d repair attempts: %w", maxRepairAttempts, parseErr)
+		}
+
+		logf("Summary was not valid JSON (%v), asking the model to repair it...\n", parseErr)
+		response, err = complete(ctx, opts, buildRepairPrompt(response, parseErr))
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func parseSummary(response string, issues []Issue) (*Summary, error) {
+	raw, err := extractJSON(response)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var ms modelSummary
+	if err := dec.Decode(&ms); err != nil {
+		return nil, fmt.Errorf("does not match schema: %w", err)
+	}
+
+	titles := make(map[int]string, len(issues))
+	for _, issue := range issues {
+		titles[issue.Number] = issue.Title
+	}
+
+	var problems []error
+	if strings.TrimSpace(ms.Overview) == "" {
+		problems = append(problems, errors.New("overview is empty"))
+	}
+	if len(ms.Themes) == 0 {
+		problems = append(problems, errors.New("themes is empty"))
+	}
+	for i, theme := range ms.Themes {
+		if strings.TrimSpace(theme.Name) == "" {
+			problems = append(problems, fmt.Errorf("themes[%d] has no name", i))
+		}
+		if theme.Count <= 0 {
+			problems = append(problems, fmt.Errorf("themes[%d] has count %d", i, theme.Count))
+		}
+		for _, n := range theme.Issues {
+			if _, ok := titles[n]; !ok {
+				problems = append(problems, fmt.Errorf("themes[%d] references unknown issue #%d", i, n))
+			}
+		}
+		if theme.Issues == nil {
+			ms.Themes[i].Issues = []int{}
+		}
+	}
+	if len(ms.TopIssues) == 0 {
+		problems = append(problems, errors.New("top_issues is empty"))
+	}
+
+	summary := &Summary{
+		Overview: strings.TrimSpace(ms.Overview),
+		Themes:   ms.Themes,
+		Patterns: ms.Patterns,
+	}
+	for i, top := range ms.TopIssues {
+		title, ok := titles[top.Number]
+		if !ok {
+			problems = append(problems, fmt.Errorf("top_issues[%d] references unknown issue #%d", i, top.Number))
+		}
+		if strings.TrimSpace(top.Reason) == "" {
+			problems = append(problems, fmt.Errorf("top_issues[%d] has no reason", i))
+		}
+		summary.TopIssues = append(summary.TopIssues, TopIssue{Number: top.Number, Title: title, Reason: top.Reason})
+	}
+	if summary.Patterns == nil {
+		summary.Patterns = []string{}
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return summary, nil
+}
+
+// extractJSON returns the outermost JSON object in s, tolerating code fences
+// or stray prose around it.
+func extractJSON(s string) ([]byte, error) {
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("no JSON object found")
+	}
+	raw := []byte(s[start : end+1])
+	if !json.Valid(raw) {
+		return nil, errors.New("malformed JSON")
+	}
+	return raw, nil
+}
+
+func buildRepairPrompt(response string, problem error) string {
+	var b strings.Builder
+
+	b.WriteString("The following response was supposed to be a JSON object summarizing issues, but it could not be used.\n\n")
+	fmt.Fprintf(&b, "Problems:\n%s\n\n", problem)
+	fmt.Fprintf(&b, "Response:\n%s\n\n", response)
+	b.WriteString("Return a corrected version as a single JSON object and nothing else, matching this schema:\n")
+	b.WriteString(jsonSchema)
+	b.WriteString("\n\nKeep the content the same where possible. Drop references to issue numbers that do not exist.")
+
+	return b.String()
+}
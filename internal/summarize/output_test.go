@@ -0,0 +1,98 @@
+package summarize
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var outputIssues = []Issue{
+	{Number: 1, Title: "Crash on start"},
+	{Number: 2, Title: "Slow sync"},
+	{Number: 3, Title: "Docs typo"},
+}
+
+const validSummary = `{
+  "overview": "Mostly bugs.",
+  "themes": [{"name": "Bugs", "count": 2, "issues": [1, 2]}],
+  "patterns": ["Startup problems"],
+  "top_issues": [{"number": 1, "reason": "Blocks everyone"}]
+}`
+
+func TestExtractJSON_CodeFence(t *testing.T) {
+	got, err := extractJSON("```json\n{\"a\": 1}\n```")
+	if err != nil {
+		t.Fatalf("extractJSON() error: %v", err)
+	}
+	if string(got) != `{"a": 1}` {
+		t.Errorf("extractJSON() = %q, want '{\"a\": 1}'", got)
+	}
+}
+
+func TestExtractJSON_NoObject(t *testing.T) {
+	if _, err := extractJSON("no json here"); err == nil {
+		t.Fatal("expected error when no JSON object is present")
+	}
+}
+
+func TestExtractJSON_Malformed(t *testing.T) {
+	if _, err := extractJSON(`{"overview": "x",}`); err == nil {
+		t.Fatal("expected error for malformed JSON")
+	}
+}
+
+func TestParseSummary_Valid(t *testing.T) {
+	got, err := parseSummary("Here you go:\n"+validSummary, outputIssues)
+	if err != nil {
+		t.Fatalf("parseSummary() error: %v", err)
+	}
+	if got.Overview != "Mostly bugs." {
+		t.Errorf("overview = %q", got.Overview)
+	}
+	if len(got.Themes) != 1 || got.Themes[0].Count != 2 {
+		t.Errorf("unexpected themes: %v", got.Themes)
+	}
+	if len(got.TopIssues) != 1 || got.TopIssues[0].Title != "Crash on start" {
+		t.Errorf("top issue title should be filled from issues, got %v", got.TopIssues)
+	}
+}
+
+func TestParseSummary_UnknownField(t *testing.T) {
+	resp := strings.Replace(validSummary, `"patterns"`, `"extra": 1, "patterns"`, 1)
+	if _, err := parseSummary(resp, outputIssues); err == nil {
+		t.Fatal("expected error for field outside the schema")
+	}
+}
+
+func TestParseSummary_UnknownIssue(t *testing.T) {
+	resp := strings.Replace(validSummary, `"number": 1`, `"number": 99`, 1)
+	_, err := parseSummary(resp, outputIssues)
+	if err == nil {
+		t.Fatal("expected error for unknown issue number")
+	}
+	if !strings.Contains(err.Error(), "#99") {
+		t.Errorf("error = %q, want it to mention #99", err)
+	}
+}
+
+func TestParseSummary_MissingContent(t *testing.T) {
+	_, err := parseSummary(`{"overview": "", "themes": [], "patterns": [], "top_issues": []}`, outputIssues)
+	if err == nil {
+		t.Fatal("expected error for empty summary")
+	}
+	for _, want := range []string{"overview", "themes", "top_issues"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want it to mention %s", err, want)
+		}
+	}
+}
+
+func TestBuildRepairPrompt(t *testing.T) {
+	prompt := buildRepairPrompt("not json", errors.New("no JSON object found"))
+
+	for _, want := range []string{"no JSON object found", "not json", `"top_issues"`} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package summarize
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+// Provider is a language model backend. Requests and responses use the
+// Messages API shapes from the claude package: claude.Client implements
+// Provider as is, and other backends translate to and from them.
+type Provider interface {
+	Send(ctx context.Context, req claude.Request) (*claude.Response, error)
+	// Stream calls onText with each text delta as it arrives.
+	Stream(ctx context.Context, req claude.Request, onText func(string)) (*claude.Response, error)
+}
+
+// systemPrompt sets the model's role for every request unless
+// Params.System replaces it. What to do with the issues is left to each
+// prompt.
+const systemPrompt = `You are an experienced open source maintainer helping a project's maintainers make sense of their issue tracker and review queue. Work only from the issues, pull requests and summaries you are given, refer to them by number, and never invent any.`
+
+// Params are request settings applied to every request. Zero values keep
+// the defaults.
+type Params struct {
+	// System replaces the built-in system prompt.
+	System        string
+	MaxTokens     int
+	Temperature   *float64
+	TopP          *float64
+	StopSequences []string
+	// UserID is passed on as request metadata, e.g. for abuse tracking.
+	UserID string
+	// MaxContinuations is how many times a reply cut off at MaxTokens is
+	// sent back for the model to continue.
+	MaxContinuations int
+	// PromptCache marks the issue listing of summary and chat prompts for
+	// the provider's prompt cache.
+	PromptCache bool
+}
+
+// NewRequest returns the request for a single prompt, with the system
+// prompt and opts.Params applied.
+func NewRequest(opts Options, prompt string) claude.Request {
+	return newChatRequest(opts, []claude.Message{{Role: "user", Content: prompt}})
+}
+
+func newChatRequest(opts Options, messages []claude.Message) claude.Request {
+	req := claude.NewChatRequest(opts.Model, messages)
+	p := opts.Params
+	req.System = systemPrompt
+	if p.System != "" {
+		req.System = p.System
+	}
+	if p.MaxTokens > 0 {
+		req.MaxTokens = p.MaxTokens
+	}
+	req.Temperature = p.Temperature
+	req.TopP = p.TopP
+	req.StopSequences = p.StopSequences
+	if p.UserID != "" {
+		req.Metadata = &claude.Metadata{UserID: p.UserID}
+	}
+	return req
+}
+
+func complete(ctx context.Context, opts Options, prompt string) (string, error) {
+	return completeMessage(ctx, opts, claude.Message{Role: "user", Content: prompt})
+}
+
+func completeStream(ctx context.Context, opts Options, prompt string, onText func(string)) (string, error) {
+	return streamMessage(ctx, opts, claude.Message{Role: "user", Content: prompt}, onText)
+}
+
+func completeMessage(ctx context.Context, opts Options, msg claude.Message) (string, error) {
+	return converse(ctx, opts, []claude.Message{msg}, false, nil)
+}
+
+func streamMessage(ctx context.Context, opts Options, msg claude.Message, onText func(string)) (string, error) {
+	return converse(ctx, opts, []claude.Message{msg}, true, onText)
+}
+
+// Prefiller is implemented by providers that carry on from a conversation
+// ending in a partial assistant message, rather than answering it afresh.
+// Wrappers report what they wrap.
+type Prefiller interface {
+	SupportsPrefill() bool
+}
+
+func supportsPrefill(p Provider) bool {
+	prefiller, ok := p.(Prefiller)
+	return ok && prefiller.SupportsPrefill()
+}
+
+// converse sends messages and returns the reply, streaming it to onText if
+// stream is set. If the provider supports prefill, a reply cut off at the
+// output token limit is sent back as the start of the assistant's turn for
+// the model to carry on from, up to opts.Params.MaxContinuations times;
+// if it is still cut off after that, the partial reply is returned with a
+// warning logged.
+func converse(ctx context.Context, opts Options, messages []claude.Message, stream bool, onText func(string)) (string, error) {
+	var text string
+	for n := 0; ; n++ {
+		msgs := messages
+		if n > 0 {
+			// The API rejects an assistant turn ending in whitespace. Only
+			// the prefill is trimmed, so the reply matches what was
+			// streamed.
+			prefill := strings.TrimRightFunc(text, unicode.IsSpace)
+			msgs = append(slices.Clip(messages), claude.Message{Role: "assistant", Content: prefill})
+		}
+		req := newChatRequest(opts, msgs)
+		var resp *claude.Response
+		var err error
+		if stream {
+			resp, err = opts.Provider.Stream(ctx, req, onText)
+		} else {
+			resp, err = opts.Provider.Send(ctx, req)
+		}
+		if resp == nil {
+			return text, err
+		}
+		if n == 0 {
+			logCache(opts, messages[0], resp)
+		}
+		text += resp.Text()
+		if err != nil || !resp.Truncated() {
+			return text, err
+		}
+		if opts.Params.MaxContinuations > 0 && !supportsPrefill(opts.Provider) {
+			logf("Warning: the reply was cut off at the %d-token output limit, and this provider can't continue it; raise --max-tokens to get all of it.\n", req.MaxTokens)
+			return text, nil
+		}
+		if n == opts.Params.MaxContinuations {
+			logf("Warning: the reply was cut off at the %d-token output limit; raise --max-tokens or --max-continuations to get all of it.\n", req.MaxTokens)
+			return text, nil
+		}
+		logf("The reply reached the %d-token output limit, asking the model to continue...\n", req.MaxTokens)
+	}
+}
+
+// summaryMessage returns the user message for a summary prompt that ends
+// with instructions. With opts.Params.PromptCache set, everything before the
+// instructions is cached: it only changes when the issues do, so later runs
+// over the same issues with other templates or output formats read it from
+// the cache.
+func summaryMessage(opts Options, prompt, instructions string) claude.Message {
+	listing, ok := strings.CutSuffix(prompt, instructions)
+	if !opts.Params.PromptCache || !ok || listing == "" {
+		return claude.Message{Role: "user", Content: prompt}
+	}
+	return claude.CachedMessage("user", listing, instructions)
+}
+
+// logCache reports how a request with a cache breakpoint used the cache.
+func logCache(opts Options, msg claude.Message, resp *claude.Response) {
+	if !msg.Cached() || isDryRun(opts) {
+		return
+	}
+	u := resp.Usage
+	switch {
+	case u.CacheReadInputTokens > 0:
+		logf("Read %d prompt tokens from the cache.\n", u.CacheReadInputTokens)
+	case u.CacheCreationInputTokens > 0:
+		logf("Cached %d prompt tokens for later runs over the same issues.\n", u.CacheCreationInputTokens)
+	default:
+		logf("The prompt wasn't cached: it may be shorter than the model's minimum, or the provider doesn't support caching.\n")
+	}
+}
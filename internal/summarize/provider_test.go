@@ -0,0 +1,206 @@
+package summarize
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+// fakeProvider replies with the canned responses in order, then with the
+// canned replies as text carrying usage, then with "ok". It records the
+// requests it was sent and the prompt ending each.
+type fakeProvider struct {
+	responses []*claude.Response
+	replies   []string
+	usage     claude.Usage
+	noPrefill bool
+	requests  []claude.Request
+	prompts   []string
+}
+
+func (f *fakeProvider) SupportsPrefill() bool {
+	return !f.noPrefill
+}
+
+func (f *fakeProvider) Send(ctx context.Context, req claude.Request) (*claude.Response, error) {
+	f.requests = append(f.requests, req)
+	f.prompts = append(f.prompts, req.Messages[len(req.Messages)-1].Content)
+	if len(f.responses) > 0 {
+		resp := f.responses[0]
+		f.responses = f.responses[1:]
+		return resp, nil
+	}
+	reply := "ok"
+	if len(f.replies) > 0 {
+		reply, f.replies = f.replies[0], f.replies[1:]
+	}
+	return &claude.Response{Content: []claude.ContentBlock{{Type: "text", Text: reply}}, Usage: f.usage}, nil
+}
+
+func (f *fakeProvider) Stream(ctx context.Context, req claude.Request, onText func(string)) (*claude.Response, error) {
+	resp, err := f.Send(ctx, req)
+	if err == nil && onText != nil {
+		onText(resp.Text())
+	}
+	return resp, err
+}
+
+func TestCondenseBatches(t *testing.T) {
+	fake := &fakeProvider{replies: []string{"partial one", "partial two"}}
+	batches := [][]Issue{{testIssue(1, "a")}, {testIssue(2, "b")}}
+
+	prompt, err := condenseBatches(context.Background(), Options{Provider: fake, Model: "m"}, subject{Repo: "o/r"}, 2, batches, summaryInstructions)
+	if err != nil {
+		t.Fatalf("condenseBatches() error: %v", err)
+	}
+	if len(fake.prompts) != 2 {
+		t.Fatalf("sent %d requests, want one per batch", len(fake.prompts))
+	}
+	if !strings.Contains(fake.prompts[1], "This is batch 2") {
+		t.Errorf("second request should be for batch 2, got:\n%s", fake.prompts[1])
+	}
+	for _, want := range []string{"partial one", "partial two", summaryInstructions} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("reduce prompt missing %q", want)
+		}
+	}
+}
+
+func TestRequestSummary_Repairs(t *testing.T) {
+	fake := &fakeProvider{replies: []string{"Sorry, here is prose.", validSummary}}
+
+	got, err := requestSummary(context.Background(), Options{Provider: fake, Model: "m"}, "o/r", claude.Message{Role: "user", Content: "prompt"}, outputIssues)
+	if err != nil {
+		t.Fatalf("requestSummary() error: %v", err)
+	}
+	if len(fake.prompts) != 2 {
+		t.Errorf("sent %d requests, want 2 (original + repair)", len(fake.prompts))
+	}
+	if !strings.Contains(fake.prompts[1], "Sorry, here is prose.") {
+		t.Error("repair prompt should include the invalid response")
+	}
+	if got.Repository != "o/r" || got.IssueCount != len(outputIssues) {
+		t.Errorf("repository/issue_count = %q/%d", got.Repository, got.IssueCount)
+	}
+}
+
+func TestRequestSummary_GivesUp(t *testing.T) {
+	fake := &fakeProvider{replies: []string{"no", "still no", "nope"}}
+
+	_, err := requestSummary(context.Background(), Options{Provider: fake, Model: "m"}, "o/r", claude.Message{Role: "user", Content: "prompt"}, outputIssues)
+	if err == nil {
+		t.Fatal("expected error after repair attempts are exhausted")
+	}
+	if len(fake.prompts) != 1+maxRepairAttempts {
+		t.Errorf("sent %d requests, want %d", len(fake.prompts), 1+maxRepairAttempts)
+	}
+}
+
+func TestNewRequest_Params(t *testing.T) {
+	req := NewRequest(Options{Model: "m"}, "prompt")
+	if req.System != systemPrompt || req.MaxTokens != 4096 || req.Temperature != nil || req.Metadata != nil {
+		t.Errorf("default request = %+v, want the built-in system prompt and no sampling settings", req)
+	}
+
+	zero := 0.0
+	req = NewRequest(Options{Model: "m", Params: Params{
+		System:        "Be terse.",
+		MaxTokens:     1000,
+		Temperature:   &zero,
+		StopSequences: []string{"END"},
+		UserID:        "u1",
+	}}, "prompt")
+	if req.System != "Be terse." || req.MaxTokens != 1000 || req.Temperature == nil || *req.Temperature != 0 {
+		t.Errorf("request = %+v, want the params applied", req)
+	}
+	if len(req.StopSequences) != 1 || req.Metadata == nil || req.Metadata.UserID != "u1" {
+		t.Errorf("stop sequences/metadata = %v/%v", req.StopSequences, req.Metadata)
+	}
+	if len(req.Messages) != 1 || req.Messages[0].Content != "prompt" {
+		t.Errorf("messages = %+v, want just the prompt", req.Messages)
+	}
+}
+
+// cutOff returns the replies as responses that are each cut off at the
+// output limit but the last, like a model running out of tokens.
+func cutOff(replies ...string) []*claude.Response {
+	responses := make([]*claude.Response, len(replies))
+	for i, reply := range replies {
+		responses[i] = &claude.Response{Content: []claude.ContentBlock{{Type: "text", Text: reply}}, StopReason: claude.StopMaxTokens}
+	}
+	responses[len(replies)-1].StopReason = "end_turn"
+	return responses
+}
+
+func TestConverse_Continues(t *testing.T) {
+	provider := &fakeProvider{responses: cutOff("The first", " half and the second.")}
+	opts := Options{Provider: provider, Model: "m", Params: Params{MaxContinuations: 2}}
+	var streamed strings.Builder
+	got, err := streamMessage(context.Background(), opts, claude.Message{Role: "user", Content: "prompt"}, func(s string) {
+		streamed.WriteString(s)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "The first half and the second." {
+		t.Errorf("reply = %q, want the two parts joined", got)
+	}
+	if len(provider.requests) != 2 {
+		t.Fatalf("sent %d requests, want 2", len(provider.requests))
+	}
+	msgs := provider.requests[1].Messages
+	if len(msgs) != 2 || msgs[1].Role != "assistant" || msgs[1].Content != "The first" {
+		t.Errorf("continuation messages = %+v, want the partial reply as the assistant's turn", msgs)
+	}
+	if streamed.String() != "The first half and the second." {
+		t.Errorf("streamed %q", streamed.String())
+	}
+}
+
+func TestConverse_StopsContinuing(t *testing.T) {
+	provider := &fakeProvider{responses: cutOff("one\n", "two", "three")}
+	got, err := complete(context.Background(), Options{Provider: provider, Model: "m", Params: Params{MaxContinuations: 1}}, "prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "one\ntwo" || len(provider.requests) != 2 {
+		t.Errorf("reply = %q after %d requests, want the partial reply after one continuation", got, len(provider.requests))
+	}
+	if prefill := provider.requests[1].Messages[1].Content; prefill != "one" {
+		t.Errorf("continuation prefill = %q, want trailing whitespace trimmed", prefill)
+	}
+
+	provider = &fakeProvider{responses: cutOff("one", "two")}
+	if got, _ := complete(context.Background(), Options{Provider: provider, Model: "m"}, "prompt"); got != "one" || len(provider.requests) != 1 {
+		t.Errorf("without continuations reply = %q after %d requests, want the cut-off reply", got, len(provider.requests))
+	}
+
+	provider = &fakeProvider{responses: cutOff("one", "two"), noPrefill: true}
+	metered := &Meter{Provider: provider}
+	opts := Options{Provider: metered, Model: "m", Params: Params{MaxContinuations: 2}}
+	if got, _ := complete(context.Background(), opts, "prompt"); got != "one" || len(provider.requests) != 1 {
+		t.Errorf("without prefill reply = %q after %d requests, want the cut-off reply", got, len(provider.requests))
+	}
+}
+
+func TestSummaryMessage(t *testing.T) {
+	prompt := "the issues\n\n" + summaryInstructions
+	if msg := summaryMessage(Options{}, prompt, summaryInstructions); msg.Cached() || msg.Content != prompt {
+		t.Errorf("without PromptCache the message = %+v, want the plain prompt", msg)
+	}
+
+	opts := Options{Params: Params{PromptCache: true}}
+	msg := summaryMessage(opts, prompt, summaryInstructions)
+	if len(msg.Blocks) != 2 || msg.Blocks[0].Text != "the issues\n\n" || msg.Blocks[0].CacheControl == nil || msg.Blocks[1].Text != summaryInstructions {
+		t.Errorf("message = %+v, want the issues cached ahead of the instructions", msg)
+	}
+	if msg.Text() != prompt {
+		t.Errorf("Text() = %q, want the whole prompt", msg.Text())
+	}
+
+	if msg := summaryMessage(opts, "a batch prompt", summaryInstructions); msg.Cached() {
+		t.Errorf("a prompt not ending with the instructions should not be cached, got %+v", msg)
+	}
+}
@@ -0,0 +1,183 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mrphil/gitissuesum/internal/truncate"
+)
+
+// maxPullBodyChars caps each pull request description in the prompt; the
+// review state matters more than the description for triaging the queue.
+const maxPullBodyChars = 300
+
+const pullsInstructions = `Please provide:
+1. A high-level summary of the review queue (2-3 sentences)
+2. Stuck pull requests: waiting long for a first review, changes requested with no push since, or no activity for weeks. Say what would unblock each one
+3. Risky pull requests: large diffs, failing CI, or big changes with little review. Say what the risk is
+4. Pull requests that are ready or nearly ready to merge
+5. Suggestions for working through the queue, such as who to nudge or what to review first
+
+Refer to pull requests by number. Be concise and actionable.`
+
+// PullSource is a Source that can also list open pull requests.
+type PullSource interface {
+	Source
+	FetchPulls(ctx context.Context, maxPulls int) ([]Pull, error)
+}
+
+type Pull struct {
+	Number int      `json:"number"`
+	Title  string   `json:"title"`
+	Body   string   `json:"body,omitempty"`
+	Author string   `json:"author"`
+	Draft  bool     `json:"draft"`
+	Labels []string `json:"labels,omitempty"`
+	// ReviewDecision is "approved", "changes_requested", or empty while no
+	// reviewer has approved or asked for changes.
+	ReviewDecision     string   `json:"review_decision,omitempty"`
+	RequestedReviewers []string `json:"requested_reviewers,omitempty"`
+	ChangedFiles       int      `json:"changed_files"`
+	Additions          int      `json:"additions"`
+	Deletions          int      `json:"deletions"`
+	// CI is "passing", "failing", "pending", or empty when no checks ran.
+	CI        string    `json:"ci,omitempty"`
+	Comments  int       `json:"comments"`
+	CreatedAt time.Time `json:"created_at"`
+	// LastPushAt is when the branch was last pushed to, as near as the
+	// source can tell.
+	LastPushAt time.Time `json:"last_push_at"`
+	URL        string    `json:"url,omitempty"`
+}
+
+// PullReport is the --output json form of a review queue summary.
+type PullReport struct {
+	Repository string       `json:"repository"`
+	Pulls      []Pull       `json:"pulls"`
+	Summary    string       `json:"summary"`
+	Usage      *UsageReport `json:"usage,omitempty"`
+}
+
+// RunPulls fetches the open pull requests of opts.Source, which must be a
+// PullSource, and writes a summary of the review queue to stdout.
+// opts.MaxIssues caps how many pull requests are fetched.
+func RunPulls(ctx context.Context, opts Options) error {
+	src, ok := opts.Source.(PullSource)
+	if !ok {
+		return fmt.Errorf("pull request summaries are not supported for %s", opts.Source.Name())
+	}
+	logf("Fetching open pull requests from %s...\n", src.Repo())
+
+	pulls, err := src.FetchPulls(ctx, opts.MaxIssues)
+	if err != nil {
+		return fmt.Errorf("failed to fetch pull requests: %w", err)
+	}
+
+	report := &PullReport{Repository: src.Repo(), Pulls: pulls}
+	if len(pulls) == 0 {
+		logf("No open pull requests found.\n")
+		if opts.Output == OutputJSON {
+			report.Pulls = []Pull{}
+			report.Usage = usageReport(opts)
+			return WriteJSON(report)
+		}
+		return nil
+	}
+
+	logf("Found %d open pull requests in %s. Sending to %s for analysis...\n", len(pulls), src.Repo(), opts.Model)
+	prompt := buildPullsPrompt(src.Name(), src.Repo(), pulls, time.Now())
+	if estimateTokens(prompt) > inputBudget(opts) {
+		return errors.New("too many pull requests to summarize at once; lower --max-prs")
+	}
+
+	if opts.Output == OutputJSON {
+		text, err := complete(ctx, opts, prompt)
+		if err != nil {
+			return fmt.Errorf("failed to get summary: %w", err)
+		}
+		report.Summary = strings.TrimSpace(text)
+		report.Usage = usageReport(opts)
+		return WriteJSON(report)
+	}
+
+	fmt.Println()
+	_, err = completeStream(ctx, opts, prompt, func(text string) {
+		fmt.Print(text)
+	})
+	fmt.Println()
+	if err != nil {
+		return fmt.Errorf("failed to get summary: %w", err)
+	}
+	return nil
+}
+
+func buildPullsPrompt(source, repo string, pulls []Pull, now time.Time) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "This is the %s pull request review queue of the repository %s.\n", source, repo)
+	fmt.Fprintf(&b, "There are %d open pull requests. Today is %s. Here they are:\n\n", len(pulls), now.Format("2006-01-02"))
+
+	for _, p := range pulls {
+		writePull(&b, p, now)
+	}
+
+	b.WriteString(pullsInstructions)
+
+	return b.String()
+}
+
+func writePull(b *strings.Builder, p Pull, now time.Time) {
+	fmt.Fprintf(b, "--- PR #%d ---\n", p.Number)
+	fmt.Fprintf(b, "Title: %s\n", p.Title)
+	fmt.Fprintf(b, "Author: %s\n", p.Author)
+	fmt.Fprintf(b, "Opened: %s (%s)\n", p.CreatedAt.Format("2006-01-02"), daysAgo(p.CreatedAt, now))
+	if !p.LastPushAt.IsZero() {
+		fmt.Fprintf(b, "Last push: %s (%s)\n", p.LastPushAt.Format("2006-01-02"), daysAgo(p.LastPushAt, now))
+	}
+	if p.Draft {
+		b.WriteString("Draft: yes\n")
+	}
+
+	switch p.ReviewDecision {
+	case "approved":
+		b.WriteString("Review: approved\n")
+	case "changes_requested":
+		b.WriteString("Review: changes requested\n")
+	default:
+		b.WriteString("Review: no approval or change request yet\n")
+	}
+	if len(p.RequestedReviewers) > 0 {
+		fmt.Fprintf(b, "Waiting on: %s\n", strings.Join(p.RequestedReviewers, ", "))
+	}
+
+	fmt.Fprintf(b, "Size: %d files changed, +%d -%d\n", p.ChangedFiles, p.Additions, p.Deletions)
+	if p.CI != "" {
+		fmt.Fprintf(b, "CI: %s\n", p.CI)
+	} else {
+		b.WriteString("CI: none\n")
+	}
+	fmt.Fprintf(b, "Comments: %d\n", p.Comments)
+
+	if len(p.Labels) > 0 {
+		fmt.Fprintf(b, "Labels: %s\n", strings.Join(p.Labels, ", "))
+	}
+	if body := truncate.String(p.Body, maxPullBodyChars); body != "" {
+		fmt.Fprintf(b, "Body: %s\n", body)
+	}
+
+	b.WriteString("\n")
+}
+
+func daysAgo(t, now time.Time) string {
+	switch days := int(now.Sub(t).Hours() / 24); days {
+	case 0:
+		return "today"
+	case 1:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
@@ -0,0 +1,80 @@
+package summarize
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakePullSource struct {
+	fakeSource
+	pulls []Pull
+}
+
+func (f *fakePullSource) FetchPulls(ctx context.Context, maxPulls int) ([]Pull, error) {
+	return f.pulls[:min(len(f.pulls), maxPulls)], nil
+}
+
+func TestBuildPullsPrompt(t *testing.T) {
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	pulls := []Pull{
+		{
+			Number:             12,
+			Title:              "Rewrite the scheduler",
+			Author:             "alice",
+			ReviewDecision:     "changes_requested",
+			RequestedReviewers: []string{"bob", "team core"},
+			ChangedFiles:       40,
+			Additions:          1200,
+			Deletions:          300,
+			CI:                 "failing",
+			CreatedAt:          now.AddDate(0, 0, -30),
+			LastPushAt:         now.AddDate(0, 0, -21),
+		},
+		{Number: 13, Title: "Fix typo", Author: "carol", Draft: true, CreatedAt: now, LastPushAt: now},
+	}
+
+	prompt := buildPullsPrompt("GitHub", "o/r", pulls, now)
+	for _, want := range []string{
+		"GitHub pull request review queue of the repository o/r",
+		"There are 2 open pull requests. Today is 2025-03-31.",
+		"--- PR #12 ---",
+		"Opened: 2025-03-01 (30 days ago)",
+		"Last push: 2025-03-10 (21 days ago)",
+		"Review: changes requested",
+		"Waiting on: bob, team core",
+		"Size: 40 files changed, +1200 -300",
+		"CI: failing",
+		"Draft: yes",
+		"CI: none",
+		"Last push: 2025-03-31 (today)",
+		"Stuck pull requests",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestRunPulls(t *testing.T) {
+	fake := &fakeProvider{replies: []string{"The queue is healthy."}}
+	src := &fakePullSource{pulls: []Pull{{Number: 1, Title: "a"}, {Number: 2, Title: "b"}, {Number: 3, Title: "c"}}}
+
+	if err := RunPulls(context.Background(), Options{Source: src, Provider: fake, Model: "m", MaxIssues: 2}); err != nil {
+		t.Fatalf("RunPulls() error: %v", err)
+	}
+	if len(fake.prompts) != 1 {
+		t.Fatalf("sent %d requests, want 1", len(fake.prompts))
+	}
+	if !strings.Contains(fake.prompts[0], "--- PR #2 ---") || strings.Contains(fake.prompts[0], "--- PR #3 ---") {
+		t.Errorf("prompt should list the first 2 pull requests only:\n%s", fake.prompts[0])
+	}
+}
+
+func TestRunPulls_Unsupported(t *testing.T) {
+	err := RunPulls(context.Background(), Options{Source: &fakeSource{}, Provider: &fakeProvider{}, Model: "m"})
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("RunPulls() error = %v, want unsupported source", err)
+	}
+}
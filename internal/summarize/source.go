@@ -0,0 +1,58 @@
+package summarize
+
+import (
+	"context"
+	"time"
+)
+
+// Source is an issue tracker holding the repository being summarized. The
+// github and gitlab packages each provide one, translating their API's
+// issues and comments into the neutral types below.
+type Source interface {
+	// Name identifies the tracker in prompts, such as "GitHub".
+	Name() string
+	// Repo is the repository's display name, such as owner/repo.
+	Repo() string
+	FetchIssues(ctx context.Context, filter Filter, maxIssues int) ([]Issue, error)
+	// FetchComments returns the comments of each numbered issue, oldest
+	// first, keyed by issue number.
+	FetchComments(ctx context.Context, numbers []int) (map[int][]Comment, error)
+}
+
+type Issue struct {
+	// Number is the issue's number within its repository, which is what
+	// users refer to it by (the IID on GitLab).
+	Number    int       `json:"number"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body,omitempty"`
+	State     string    `json:"state"`
+	Author    string    `json:"author"`
+	Labels    []string  `json:"labels,omitempty"`
+	Comments  int       `json:"comments"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	URL       string    `json:"url,omitempty"`
+}
+
+type Comment struct {
+	Author string
+	Body   string
+	// Maintainer is set for authors with a formal role in the repository,
+	// whose comments usually carry decisions.
+	Maintainer bool
+	CreatedAt  time.Time
+}
+
+// Filter selects which issues to fetch. Values follow the GitHub flags the
+// command line accepts, and each Source maps them to its own API. Zero
+// values mean no filtering, except State, which sources treat as "open".
+type Filter struct {
+	State     string
+	Labels    []string
+	Assignee  string
+	Author    string
+	Milestone string
+	Since     time.Time
+	Sort      string
+	Direction string
+}
@@ -2,86 +2,270 @@ package summarize
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/mrphil/gitissuesum/internal/claude"
-	"github.com/mrphil/gitissuesum/internal/github"
+	"github.com/mrphil/gitissuesum/internal/truncate"
 )
 
+// maxBodyChars caps each issue body where the budgeter hasn't set a limit.
 const maxBodyChars = 500
 
-func Run(ctx context.Context, owner, repo, apiKey, githubToken, model string, maxIssues int) error {
-	fmt.Printf("Fetching issues from %s/%s...\n", owner, repo)
+const summaryInstructions = `Please provide:
+1. A high-level summary of the issues (2-3 sentences)
+2. Main themes/categories you see, with approximate counts
+3. Notable patterns (e.g., recurring problems, areas needing attention)
+4. The top 5 most important issues and why they stand out
 
-	issues, err := github.FetchIssues(ctx, owner, repo, githubToken, maxIssues)
-	if err != nil {
-		return fmt.Errorf("failed to fetch issues: %w", err)
+Be concise and actionable.`
+
+type Options struct {
+	Source    Source
+	Provider  Provider
+	Model     string
+	MaxIssues int
+	Output    string
+	Filter    Filter
+	// IncludeComments adds a digest of each issue's discussion to the prompt.
+	IncludeComments bool
+	// Previous, if set, is an earlier snapshot to report changes against
+	// instead of summarizing every issue.
+	Previous *Snapshot
+	// Instructions, if set, replaces the closing instructions of text
+	// summaries, which say what the summary should cover.
+	Instructions Instructions
+	// InputBudget caps the input tokens of each request. Zero means three
+	// quarters of the model's context window.
+	InputBudget int
+	// CountTokens checks the prompt size with the provider's token counter,
+	// if it has one, instead of relying on the estimate.
+	CountTokens bool
+	// Params sets the system prompt and sampling settings of requests.
+	Params Params
+}
+
+// Instructions renders the closing instructions of a summary prompt.
+type Instructions interface {
+	Render(data PromptData) (string, error)
+}
+
+// PromptData is what Instructions can draw on: the issues being summarized
+// and where they come from.
+type PromptData struct {
+	Source string
+	Repo   string
+	State  string
+	// Description names the issue set, e.g. "GitHub open issues".
+	Description string
+	Issues      []Issue
+	// Comments holds each issue's comments by number when they were
+	// fetched.
+	Comments map[int][]Comment
+}
+
+// subject describes the issue set being summarized, for use in prompts.
+type subject struct {
+	// Source names the issue tracker, such as GitHub.
+	Source   string
+	Repo     string
+	State    string
+	Comments map[int][]Comment
+	// Detail holds the budgeter's limits for each issue's body and digest,
+	// by number; issues missing from it get defaultDetail.
+	Detail map[int]detail
+}
+
+func (s subject) detail(number int) detail {
+	if d, ok := s.Detail[number]; ok {
+		return d
 	}
+	return defaultDetail
+}
 
-	if len(issues) == 0 {
-		fmt.Println("No open issues found.")
-		return nil
+// describe names the issue set, e.g. "GitHub open issues".
+func (s subject) describe() string {
+	if s.Source == "" {
+		return s.issues()
 	}
+	return s.Source + " " + s.issues()
+}
 
-	fmt.Printf("Found %d issues. Sending to Claude for analysis...\n", len(issues))
+func (s subject) issues() string {
+	switch s.State {
+	case "closed":
+		return "closed issues"
+	case "all":
+		return "issues (open and closed)"
+	}
+	return "open issues"
+}
 
-	prompt := buildPrompt(owner, repo, issues)
+// Run fetches the issues, writes their summary to stdout and returns a
+// snapshot of both. With opts.Previous set, the summary covers only what
+// changed since that snapshot.
+func Run(ctx context.Context, opts Options) (*Snapshot, error) {
+	subj := subject{Source: opts.Source.Name(), Repo: opts.Source.Repo(), State: opts.Filter.State}
+	logf("Fetching issues from %s...\n", subj.Repo)
 
-	response, err := claude.SendMessage(ctx, apiKey, model, prompt)
+	issues, err := opts.Source.FetchIssues(ctx, opts.Filter, opts.MaxIssues)
 	if err != nil {
-		return fmt.Errorf("failed to get summary from Claude: %w", err)
+		return nil, fmt.Errorf("failed to fetch issues: %w", err)
 	}
 
-	fmt.Println()
-	fmt.Println(response)
-	return nil
+	snap := &Snapshot{
+		Source:     subj.Source,
+		Repository: subj.Repo,
+		State:      opts.Filter.State,
+		TakenAt:    time.Now().UTC(),
+		Issues:     issues,
+	}
+	if opts.Previous != nil {
+		snap.Summary, err = summarizeChanges(ctx, opts, subj, issues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get summary: %w", err)
+		}
+		return snap, nil
+	}
+	snap.Summary, err = summarizeIssues(ctx, opts, subj, issues)
+	if err != nil {
+		return nil, err
+	}
+	return snap, nil
 }
 
-func buildPrompt(owner, repo string, issues []github.Issue) string {
-	var b strings.Builder
+func summarizeIssues(ctx context.Context, opts Options, subj subject, issues []Issue) (string, error) {
+	if len(issues) == 0 {
+		logf("No matching issues found.\n")
+		if opts.Output == OutputJSON {
+			return "", WriteJSON(&Summary{Repository: subj.Repo, Themes: []Theme{}, Patterns: []string{}, TopIssues: []TopIssue{}, Usage: usageReport(opts)})
+		}
+		return "", nil
+	}
 
-	fmt.Fprintf(&b, "You are analyzing open GitHub issues for the repository %s/%s.\n", owner, repo)
-	fmt.Fprintf(&b, "There are %d open issues. Here they are:\n\n", len(issues))
+	msg, err := summaryPrompt(ctx, opts, subj, issues)
+	if err != nil {
+		return "", err
+	}
+	if isDryRun(opts) {
+		_, err := completeMessage(ctx, opts, msg)
+		return "", err
+	}
 
-	for _, issue := range issues {
-		fmt.Fprintf(&b, "--- Issue #%d ---\n", issue.Number)
-		fmt.Fprintf(&b, "Title: %s\n", issue.Title)
-		fmt.Fprintf(&b, "Author: %s\n", issue.User.Login)
-		fmt.Fprintf(&b, "Created: %s\n", issue.CreatedAt.Format("2006-01-02"))
-		fmt.Fprintf(&b, "Comments: %d\n", issue.Comments)
-
-		if len(issue.Labels) > 0 {
-			labels := make([]string, len(issue.Labels))
-			for i, l := range issue.Labels {
-				labels[i] = l.Name
-			}
-			fmt.Fprintf(&b, "Labels: %s\n", strings.Join(labels, ", "))
+	if opts.Output == OutputJSON {
+		summary, err := requestSummary(ctx, opts, subj.Repo, msg, issues)
+		if err != nil {
+			return "", fmt.Errorf("failed to get summary: %w", err)
+		}
+		data, err := json.Marshal(summary)
+		if err != nil {
+			return "", err
 		}
+		summary.Usage = usageReport(opts)
+		return string(data), WriteJSON(summary)
+	}
+
+	fmt.Println()
+	text, err := streamMessage(ctx, opts, msg, func(text string) {
+		fmt.Print(text)
+	})
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to get summary: %w", err)
+	}
+	return text, nil
+}
 
-		body := truncate(issue.Body, maxBodyChars)
-		if body != "" {
-			fmt.Fprintf(&b, "Body: %s\n", body)
+// summaryPrompt fetches comments if asked to and returns the message asking
+// for the final summary, fitted to the input budget.
+func summaryPrompt(ctx context.Context, opts Options, subj subject, issues []Issue) (claude.Message, error) {
+	var err error
+	if opts.IncludeComments {
+		subj.Comments, err = fetchComments(ctx, opts.Source, issues)
+		if err != nil {
+			return claude.Message{}, fmt.Errorf("failed to fetch comments: %w", err)
 		}
+	}
 
-		b.WriteString("\n")
+	logf("Found %d issues in %s. Sending to %s for analysis...\n", len(issues), subj.Repo, opts.Model)
+
+	instructions := summaryInstructions
+	switch {
+	case opts.Output == OutputJSON:
+		instructions = jsonInstructions
+	case opts.Instructions != nil:
+		instructions, err = opts.Instructions.Render(PromptData{
+			Source:      subj.Source,
+			Repo:        subj.Repo,
+			State:       subj.State,
+			Description: subj.describe(),
+			Issues:      issues,
+			Comments:    subj.Comments,
+		})
+		if err != nil {
+			return claude.Message{}, fmt.Errorf("failed to render prompt template: %w", err)
+		}
 	}
 
-	b.WriteString(`Please provide:
-1. A high-level summary of the open issues (2-3 sentences)
-2. Main themes/categories you see, with approximate counts
-3. Notable patterns (e.g., recurring problems, areas needing attention)
-4. The top 5 most important issues and why they stand out
+	prompt, err := fitPrompt(ctx, opts, subj, issues, instructions)
+	if err != nil {
+		return claude.Message{}, err
+	}
+	return summaryMessage(opts, prompt, instructions), nil
+}
 
-Be concise and actionable.`)
+func buildPrompt(subj subject, issues []Issue, instructions string) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "These are the %s of the repository %s.\n", subj.describe(), subj.Repo)
+	fmt.Fprintf(&b, "There are %d %s. Here they are:\n\n", len(issues), subj.issues())
+
+	for _, issue := range issues {
+		writeIssue(&b, issue, subj.Comments[issue.Number], subj.detail(issue.Number))
+	}
+
+	b.WriteString(instructions)
 
 	return b.String()
 }
 
-func truncate(s string, maxLen int) string {
-	s = strings.TrimSpace(s)
-	if len(s) <= maxLen {
-		return s
+func writeIssue(b *strings.Builder, issue Issue, comments []Comment, d detail) {
+	fmt.Fprintf(b, "--- Issue #%d ---\n", issue.Number)
+	fmt.Fprintf(b, "Title: %s\n", issue.Title)
+	fmt.Fprintf(b, "Author: %s\n", issue.Author)
+	if issue.State != "" && issue.State != "open" {
+		fmt.Fprintf(b, "State: %s\n", issue.State)
+	}
+	fmt.Fprintf(b, "Created: %s\n", issue.CreatedAt.Format("2006-01-02"))
+	fmt.Fprintf(b, "Comments: %d\n", issue.Comments)
+
+	if len(issue.Labels) > 0 {
+		fmt.Fprintf(b, "Labels: %s\n", strings.Join(issue.Labels, ", "))
 	}
-	return s[:maxLen] + "..."
+
+	body := truncate.String(issue.Body, d.body)
+	if body != "" {
+		fmt.Fprintf(b, "Body: %s\n", body)
+	}
+
+	writeDigest(b, comments, d.digest)
+
+	b.WriteString("\n")
+}
+
+// WriteJSON writes v to stdout as indented JSON. HTML characters are left
+// as they are, since issue titles and summaries are full of <, > and &.
+func WriteJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// logf reports progress on stderr so stdout carries only the summary.
+func logf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format, args...)
 }
@@ -1,51 +1,25 @@
 package summarize
 
 import (
+	"context"
 	"strings"
 	"testing"
 	"time"
-
-	"github.com/mrphil/gitissuesum/internal/github"
 )
 
-func TestTruncate_Short(t *testing.T) {
-	if got := truncate("hello", 10); got != "hello" {
-		t.Errorf("truncate('hello', 10) = %q, want 'hello'", got)
-	}
-}
-
-func TestTruncate_ExactLength(t *testing.T) {
-	if got := truncate("hello", 5); got != "hello" {
-		t.Errorf("truncate('hello', 5) = %q, want 'hello'", got)
-	}
-}
-
-func TestTruncate_OverLength(t *testing.T) {
-	got := truncate("hello world", 5)
-	if got != "hello..." {
-		t.Errorf("truncate('hello world', 5) = %q, want 'hello...'", got)
-	}
-}
-
-func TestTruncate_WhitespaceTrimming(t *testing.T) {
-	if got := truncate("  hi  ", 10); got != "hi" {
-		t.Errorf("truncate('  hi  ', 10) = %q, want 'hi'", got)
-	}
-}
-
 func TestBuildPrompt_SingleIssue(t *testing.T) {
-	issues := []github.Issue{
+	issues := []Issue{
 		{
 			Number:    42,
 			Title:     "Test issue",
 			Body:      "Some body text",
-			User:      github.User{Login: "alice"},
+			Author:    "alice",
 			CreatedAt: time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
 			Comments:  3,
 		},
 	}
 
-	prompt := buildPrompt("owner", "repo", issues)
+	prompt := buildPrompt(subject{Repo: "owner/repo"}, issues, summaryInstructions)
 
 	checks := []string{
 		"owner/repo",
@@ -65,12 +39,12 @@ func TestBuildPrompt_SingleIssue(t *testing.T) {
 }
 
 func TestBuildPrompt_MultipleIssues(t *testing.T) {
-	issues := []github.Issue{
-		{Number: 1, Title: "First", User: github.User{Login: "a"}, CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
-		{Number: 2, Title: "Second", User: github.User{Login: "b"}, CreatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+	issues := []Issue{
+		{Number: 1, Title: "First", Author: "a", CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Number: 2, Title: "Second", Author: "b", CreatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
 	}
 
-	prompt := buildPrompt("o", "r", issues)
+	prompt := buildPrompt(subject{Repo: "o/r"}, issues, summaryInstructions)
 
 	if !strings.Contains(prompt, "2 open issues") {
 		t.Error("prompt should mention 2 open issues")
@@ -81,17 +55,17 @@ func TestBuildPrompt_MultipleIssues(t *testing.T) {
 }
 
 func TestBuildPrompt_Labels(t *testing.T) {
-	issues := []github.Issue{
+	issues := []Issue{
 		{
 			Number:    1,
 			Title:     "Labeled",
-			User:      github.User{Login: "a"},
+			Author:    "a",
 			CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
-			Labels:    []github.Label{{Name: "bug"}, {Name: "urgent"}},
+			Labels:    []string{"bug", "urgent"},
 		},
 	}
 
-	prompt := buildPrompt("o", "r", issues)
+	prompt := buildPrompt(subject{Repo: "o/r"}, issues, summaryInstructions)
 
 	if !strings.Contains(prompt, "Labels: bug, urgent") {
 		t.Errorf("prompt missing labels, got:\n%s", prompt)
@@ -99,17 +73,17 @@ func TestBuildPrompt_Labels(t *testing.T) {
 }
 
 func TestBuildPrompt_EmptyBody(t *testing.T) {
-	issues := []github.Issue{
+	issues := []Issue{
 		{
 			Number:    1,
 			Title:     "No body",
-			User:      github.User{Login: "a"},
+			Author:    "a",
 			CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
 			Body:      "",
 		},
 	}
 
-	prompt := buildPrompt("o", "r", issues)
+	prompt := buildPrompt(subject{Repo: "o/r"}, issues, summaryInstructions)
 
 	if strings.Contains(prompt, "Body:") {
 		t.Error("prompt should not contain Body: line for empty body")
@@ -118,17 +92,17 @@ func TestBuildPrompt_EmptyBody(t *testing.T) {
 
 func TestBuildPrompt_BodyTruncation(t *testing.T) {
 	longBody := strings.Repeat("x", 600)
-	issues := []github.Issue{
+	issues := []Issue{
 		{
 			Number:    1,
 			Title:     "Long body",
-			User:      github.User{Login: "a"},
+			Author:    "a",
 			CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
 			Body:      longBody,
 		},
 	}
 
-	prompt := buildPrompt("o", "r", issues)
+	prompt := buildPrompt(subject{Repo: "o/r"}, issues, summaryInstructions)
 
 	if !strings.Contains(prompt, "...") {
 		t.Error("long body should be truncated with ...")
@@ -138,3 +112,73 @@ func TestBuildPrompt_BodyTruncation(t *testing.T) {
 		t.Error("full 600-char body should not appear in prompt")
 	}
 }
+
+func TestBuildPrompt_ClosedState(t *testing.T) {
+	issues := []Issue{
+		{
+			Number:    1,
+			Title:     "Fixed",
+			State:     "closed",
+			Author:    "a",
+			CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	prompt := buildPrompt(subject{Repo: "o/r", State: "closed"}, issues, summaryInstructions)
+
+	if !strings.Contains(prompt, "1 closed issues") {
+		t.Errorf("prompt should describe closed issues, got:\n%s", prompt)
+	}
+	if !strings.Contains(prompt, "State: closed") {
+		t.Error("prompt should include the issue state when it is not open")
+	}
+}
+
+func TestBuildPrompt_SourceName(t *testing.T) {
+	issues := []Issue{{Number: 1, Title: "MR pipeline fails", Author: "a"}}
+
+	prompt := buildPrompt(subject{Source: "GitLab", Repo: "g/sub/p"}, issues, summaryInstructions)
+
+	if !strings.Contains(prompt, "These are the GitLab open issues of the repository g/sub/p") {
+		t.Errorf("prompt should name the source, got:\n%s", prompt)
+	}
+}
+
+// staticInstructions renders fixed text and records the data it was given.
+type staticInstructions struct {
+	text string
+	data PromptData
+}
+
+func (s *staticInstructions) Render(data PromptData) (string, error) {
+	s.data = data
+	return s.text, nil
+}
+
+func TestSummaryPrompt_Instructions(t *testing.T) {
+	instructions := &staticInstructions{text: "List the release blockers."}
+	src := &fakeSource{issues: []Issue{{Number: 1, Title: "Crash"}}}
+	opts := Options{Source: src, Model: "m", Instructions: instructions}
+	subj := subject{Source: "GitHub", Repo: "o/r"}
+
+	msg, err := summaryPrompt(context.Background(), opts, subj, src.issues)
+	if err != nil {
+		t.Fatalf("summaryPrompt() error: %v", err)
+	}
+	prompt := msg.Text()
+	if !strings.HasSuffix(prompt, "List the release blockers.") || strings.Contains(prompt, summaryInstructions) {
+		t.Errorf("prompt should end with the custom instructions:\n%s", prompt)
+	}
+	if instructions.data.Repo != "o/r" || instructions.data.Description != "GitHub open issues" || len(instructions.data.Issues) != 1 {
+		t.Errorf("instructions got data %+v", instructions.data)
+	}
+
+	opts.Output = OutputJSON
+	msg, err = summaryPrompt(context.Background(), opts, subj, src.issues)
+	if err != nil {
+		t.Fatalf("summaryPrompt() error: %v", err)
+	}
+	if !strings.HasSuffix(msg.Text(), jsonInstructions) {
+		t.Error("JSON output should keep the schema instructions")
+	}
+}
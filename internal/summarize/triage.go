@@ -0,0 +1,324 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+// maxTriageRounds caps the round trips of a triage conversation, in case
+// the model keeps calling tools.
+const maxTriageRounds = 10
+
+const triageInstructions = `Triage these issues with the tools provided: suggest labels for issues missing
+the right ones, mark duplicates, suggest closing issues that are resolved,
+stale, out of scope or not actionable, and set the priority of issues that
+need one. Act only where the issues give you reason to, and leave the rest
+alone. Give a short reason with every call; a maintainer will review each
+suggestion before anything changes. Make as many calls as you need, several
+at a time. When you are done, reply with a brief note on anything that needs
+a maintainer's judgement.`
+
+// Triage action kinds, which are also the names of the tools the model
+// calls.
+const (
+	ActionLabels    = "suggest_labels"
+	ActionDuplicate = "mark_duplicate"
+	ActionClose     = "suggest_close"
+	ActionPriority  = "set_priority"
+)
+
+// priorities are the levels set_priority accepts, most urgent first.
+var priorities = []string{"critical", "high", "medium", "low"}
+
+var triageTools = []claude.Tool{
+	{
+		Name:        ActionLabels,
+		Description: "Suggest labels to add to an issue. Prefer labels the repository already uses.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{` +
+			`"issue":{"type":"integer","description":"Issue number"},` +
+			`"labels":{"type":"array","items":{"type":"string"},"minItems":1,"description":"Labels to add"},` +
+			`"reason":{"type":"string"}},"required":["issue","labels","reason"]}`),
+	},
+	{
+		Name:        ActionDuplicate,
+		Description: "Mark an issue as a duplicate of another, which stays open.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{` +
+			`"issue":{"type":"integer","description":"The duplicate issue's number"},` +
+			`"of":{"type":"integer","description":"The number of the issue to keep"},` +
+			`"reason":{"type":"string"}},"required":["issue","of","reason"]}`),
+	},
+	{
+		Name:        ActionClose,
+		Description: "Suggest closing an issue that is resolved, stale, out of scope or not actionable.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{` +
+			`"issue":{"type":"integer","description":"Issue number"},` +
+			`"reason":{"type":"string","description":"Why it can be closed, suitable for a closing comment"}},` +
+			`"required":["issue","reason"]}`),
+	},
+	{
+		Name:        ActionPriority,
+		Description: "Set an issue's priority.",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{` +
+			`"issue":{"type":"integer","description":"Issue number"},` +
+			`"level":{"type":"string","enum":["critical","high","medium","low"]},` +
+			`"reason":{"type":"string"}},"required":["issue","level","reason"]}`),
+	},
+}
+
+// Action is one triage action the model suggested.
+type Action struct {
+	Kind  string `json:"action"`
+	Issue int    `json:"issue"`
+	Title string `json:"title"`
+	// Labels is set for suggest_labels, Of for mark_duplicate and Priority
+	// for set_priority.
+	Labels   []string `json:"labels,omitempty"`
+	Of       int      `json:"of,omitempty"`
+	Priority string   `json:"priority,omitempty"`
+	Reason   string   `json:"reason"`
+}
+
+// TriagePlan is the reviewable result of a triage run, as written with
+// --output json.
+type TriagePlan struct {
+	Repository string   `json:"repository"`
+	IssueCount int      `json:"issue_count"`
+	Actions    []Action `json:"actions"`
+	// Notes is the model's closing remark, if it made one.
+	Notes string       `json:"notes,omitempty"`
+	Usage *UsageReport `json:"usage,omitempty"`
+}
+
+// toolInput is the union of the triage tools' inputs.
+type toolInput struct {
+	Issue  int      `json:"issue"`
+	Labels []string `json:"labels"`
+	Of     int      `json:"of"`
+	Level  string   `json:"level"`
+	Reason string   `json:"reason"`
+}
+
+// Triage fetches the issues of opts.Source, and their comments if
+// opts.IncludeComments is set, and has the model go through them with the
+// triage tools. Each call is checked against the issues and collected into
+// the plan; invalid calls are reported back to the model so it can correct
+// them. Nothing is changed in the repository.
+func Triage(ctx context.Context, opts Options) (*TriagePlan, error) {
+	subj := subject{Source: opts.Source.Name(), Repo: opts.Source.Repo(), State: opts.Filter.State}
+	logf("Fetching issues from %s...\n", subj.Repo)
+
+	issues, err := opts.Source.FetchIssues(ctx, opts.Filter, opts.MaxIssues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issues: %w", err)
+	}
+	plan := &TriagePlan{Repository: subj.Repo, IssueCount: len(issues), Actions: []Action{}}
+	if len(issues) == 0 {
+		logf("No matching issues found.\n")
+		plan.Usage = usageReport(opts)
+		return plan, nil
+	}
+
+	if opts.IncludeComments {
+		subj.Comments, err = fetchComments(ctx, opts.Source, issues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch comments: %w", err)
+		}
+	}
+
+	instructions := triageInstructions
+	if labels := repoLabels(issues); len(labels) > 0 {
+		instructions = fmt.Sprintf("Labels in use in the repository: %s.\n\n%s", strings.Join(labels, ", "), triageInstructions)
+	}
+	// Leave room for the suggestions and their results, which are sent
+	// back with every round.
+	listingOpts := opts
+	listingOpts.InputBudget = inputBudget(opts) * 2 / 3
+	prompt, err := fitPrompt(ctx, listingOpts, subj, issues, instructions)
+	if err != nil {
+		return nil, err
+	}
+	logf("Found %d issues in %s. Sending to %s for triage...\n", len(issues), subj.Repo, opts.Model)
+
+	messages := []claude.Message{summaryMessage(opts, prompt, instructions)}
+	for round := 1; ; round++ {
+		req := newChatRequest(opts, messages)
+		req.Tools = triageTools
+		resp, err := opts.Provider.Send(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get triage suggestions: %w", err)
+		}
+		if round == 1 {
+			logCache(opts, messages[0], resp)
+		}
+
+		// A reply cut off at the output limit can still hold complete tool
+		// calls ahead of the cut, so those are kept either way.
+		uses := resp.ToolUses()
+		results := make([]claude.ContentBlock, len(uses))
+		for i, use := range uses {
+			reply, err := plan.apply(use, issues)
+			if err != nil {
+				results[i] = claude.ToolResult(use.ID, err.Error(), true)
+				continue
+			}
+			results[i] = claude.ToolResult(use.ID, reply, false)
+		}
+		if len(uses) > 0 {
+			logf("Collected %d suggestions so far...\n", len(plan.Actions))
+		}
+
+		if resp.StopReason != claude.StopToolUse {
+			if resp.Truncated() {
+				logf("Warning: the reply was cut off at the %d-token output limit, so the rest of it is missing; raise --max-tokens to let the model finish.\n", req.MaxTokens)
+			}
+			if len(uses) == 0 {
+				plan.Notes = strings.TrimSpace(resp.Text())
+			}
+			break
+		}
+		if round == maxTriageRounds {
+			logf("Stopping after %d rounds of suggestions; the plan may be incomplete.\n", maxTriageRounds)
+			break
+		}
+		messages = append(messages,
+			claude.Message{Role: "assistant", Blocks: replyBlocks(resp)},
+			claude.Message{Role: "user", Blocks: results})
+	}
+
+	plan.Usage = usageReport(opts)
+	return plan, nil
+}
+
+// replyBlocks returns the content of resp to send back as the assistant's
+// turn. Empty text blocks are dropped, since the API rejects them.
+func replyBlocks(resp *claude.Response) []claude.ContentBlock {
+	return slices.DeleteFunc(slices.Clone(resp.Content), func(b claude.ContentBlock) bool {
+		return b.Type == "text" && strings.TrimSpace(b.Text) == ""
+	})
+}
+
+// apply checks a tool call and adds it to the plan, returning the reply for
+// the model. A later call of the same kind for the same issue replaces the
+// earlier one.
+func (p *TriagePlan) apply(use claude.ContentBlock, issues []Issue) (string, error) {
+	if !slices.ContainsFunc(triageTools, func(t claude.Tool) bool { return t.Name == use.Name }) {
+		return "", fmt.Errorf("unknown tool %q", use.Name)
+	}
+	var in toolInput
+	if err := json.Unmarshal(use.Input, &in); err != nil {
+		return "", fmt.Errorf("invalid input: %v", err)
+	}
+	i := slices.IndexFunc(issues, func(issue Issue) bool { return issue.Number == in.Issue })
+	if i < 0 {
+		return "", fmt.Errorf("issue #%d is not among the issues given", in.Issue)
+	}
+	issue := issues[i]
+	action := Action{Kind: use.Name, Issue: issue.Number, Title: issue.Title, Reason: strings.TrimSpace(in.Reason)}
+
+	switch use.Name {
+	case ActionLabels:
+		for _, label := range in.Labels {
+			label = strings.TrimSpace(label)
+			if label != "" && !slices.Contains(issue.Labels, label) && !slices.Contains(action.Labels, label) {
+				action.Labels = append(action.Labels, label)
+			}
+		}
+		if len(action.Labels) == 0 {
+			return "", fmt.Errorf("issue #%d already has those labels", issue.Number)
+		}
+	case ActionDuplicate:
+		if in.Of == issue.Number {
+			return "", fmt.Errorf("an issue can't be a duplicate of itself")
+		}
+		if !slices.ContainsFunc(issues, func(issue Issue) bool { return issue.Number == in.Of }) {
+			return "", fmt.Errorf("issue #%d is not among the issues given", in.Of)
+		}
+		action.Of = in.Of
+	case ActionPriority:
+		level := strings.ToLower(strings.TrimSpace(in.Level))
+		if !slices.Contains(priorities, level) {
+			return "", fmt.Errorf("invalid level %q, expected one of %s", in.Level, strings.Join(priorities, ", "))
+		}
+		action.Priority = level
+	}
+
+	j := slices.IndexFunc(p.Actions, func(a Action) bool { return a.Kind == action.Kind && a.Issue == action.Issue })
+	if j >= 0 {
+		p.Actions[j] = action
+		return "Replaced the earlier suggestion.", nil
+	}
+	p.Actions = append(p.Actions, action)
+	return "Recorded.", nil
+}
+
+// repoLabels returns the labels used across issues, sorted.
+func repoLabels(issues []Issue) []string {
+	var labels []string
+	for _, issue := range issues {
+		labels = append(labels, issue.Labels...)
+	}
+	slices.Sort(labels)
+	return slices.Compact(labels)
+}
+
+// WriteText writes the plan as a Markdown checklist grouped by issue, in
+// the order the issues were first acted on, for a maintainer to work
+// through.
+func (p *TriagePlan) WriteText(w io.Writer) error {
+	var b strings.Builder
+	if len(p.Actions) == 0 {
+		fmt.Fprintf(&b, "No triage actions suggested for %d issues in %s.\n", p.IssueCount, p.Repository)
+	} else {
+		var order []int
+		for _, a := range p.Actions {
+			if !slices.Contains(order, a.Issue) {
+				order = append(order, a.Issue)
+			}
+		}
+		fmt.Fprintf(&b, "# Triage plan for %s\n\n", p.Repository)
+		fmt.Fprintf(&b, "%d suggested actions on %d of %d issues.\n", len(p.Actions), len(order), p.IssueCount)
+		for _, n := range order {
+			first := true
+			for _, a := range p.Actions {
+				if a.Issue != n {
+					continue
+				}
+				if first {
+					fmt.Fprintf(&b, "\n## #%d %s\n\n", a.Issue, a.Title)
+					first = false
+				}
+				fmt.Fprintf(&b, "- [ ] %s", a.describe())
+				if a.Reason != "" {
+					fmt.Fprintf(&b, ": %s", a.Reason)
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+	if p.Notes != "" {
+		fmt.Fprintf(&b, "\n## Notes\n\n%s\n", p.Notes)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// describe says what the action does, e.g. "Add labels bug, ui".
+func (a Action) describe() string {
+	switch a.Kind {
+	case ActionLabels:
+		return "Add labels " + strings.Join(a.Labels, ", ")
+	case ActionDuplicate:
+		return fmt.Sprintf("Close as a duplicate of #%d", a.Of)
+	case ActionClose:
+		return "Close"
+	case ActionPriority:
+		return "Set priority " + a.Priority
+	}
+	return a.Kind
+}
@@ -0,0 +1,138 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+func toolUse(id, name, input string) claude.ContentBlock {
+	return claude.ContentBlock{Type: "tool_use", ID: id, Name: name, Input: json.RawMessage(input)}
+}
+
+func TestTriage(t *testing.T) {
+	crash := testIssue(1, "crash on start")
+	crash.Labels = []string{"bug"}
+	src := &fakeSource{issues: []Issue{crash, testIssue(2, "crashes when starting"), testIssue(3, "typo in docs")}}
+	provider := &fakeProvider{responses: []*claude.Response{
+		{StopReason: claude.StopToolUse, Content: []claude.ContentBlock{
+			{Type: "text", Text: "Looking at these."},
+			toolUse("a", ActionLabels, `{"issue":3,"labels":["docs"],"reason":"Documentation fix"}`),
+			toolUse("b", ActionDuplicate, `{"issue":2,"of":1,"reason":"Same crash"}`),
+			toolUse("c", ActionPriority, `{"issue":1,"level":"urgent","reason":"Crashes"}`),
+			toolUse("d", ActionClose, `{"issue":42,"reason":"Stale"}`),
+		}},
+		{StopReason: claude.StopToolUse, Content: []claude.ContentBlock{
+			toolUse("e", ActionPriority, `{"issue":1,"level":"critical","reason":"Crashes on start"}`),
+			toolUse("f", ActionLabels, `{"issue":1,"labels":["bug"],"reason":"It's a bug"}`),
+		}},
+		{StopReason: "end_turn", Content: []claude.ContentBlock{{Type: "text", Text: "Check whether #1 is fixed on main."}}},
+	}}
+
+	plan, err := Triage(context.Background(), Options{Source: src, Provider: provider, Model: "m"})
+	if err != nil {
+		t.Fatalf("Triage() error: %v", err)
+	}
+	if len(provider.requests) != 3 || len(provider.requests[0].Tools) != len(triageTools) {
+		t.Fatalf("sent %d requests, want 3 with the triage tools", len(provider.requests))
+	}
+	if !strings.Contains(provider.requests[0].Messages[0].Text(), "Labels in use in the repository: bug.") {
+		t.Error("the prompt should list the labels in use")
+	}
+
+	msgs := provider.requests[1].Messages
+	if len(msgs) != 3 || msgs[1].Role != "assistant" || len(msgs[1].Blocks) != 5 {
+		t.Fatalf("second request should carry the tool calls back, got %+v", msgs)
+	}
+	results := msgs[2].Blocks
+	if len(results) != 4 || results[0].ToolUseID != "a" || results[0].IsError || !results[2].IsError || !results[3].IsError {
+		t.Errorf("tool results = %+v, want the invalid level and the unknown issue reported as errors", results)
+	}
+	if !strings.Contains(results[3].Content, "#42") {
+		t.Errorf("error for the unknown issue = %q", results[3].Content)
+	}
+	if last := provider.requests[2].Messages[4].Blocks; last[0].IsError || !last[1].IsError {
+		t.Errorf("second round results = %+v, want the label the issue already has rejected", last)
+	}
+
+	want := []Action{
+		{Kind: ActionLabels, Issue: 3, Title: "Issue", Labels: []string{"docs"}, Reason: "Documentation fix"},
+		{Kind: ActionDuplicate, Issue: 2, Title: "Issue", Of: 1, Reason: "Same crash"},
+		{Kind: ActionPriority, Issue: 1, Title: "Issue", Priority: "critical", Reason: "Crashes on start"},
+	}
+	if len(plan.Actions) != len(want) {
+		t.Fatalf("actions = %+v, want %+v", plan.Actions, want)
+	}
+	for i := range want {
+		if plan.Actions[i].Kind != want[i].Kind || plan.Actions[i].Issue != want[i].Issue || plan.Actions[i].Of != want[i].Of ||
+			plan.Actions[i].Priority != want[i].Priority || strings.Join(plan.Actions[i].Labels, ",") != strings.Join(want[i].Labels, ",") {
+			t.Errorf("action %d = %+v, want %+v", i, plan.Actions[i], want[i])
+		}
+	}
+	if plan.Notes != "Check whether #1 is fixed on main." || plan.IssueCount != 3 {
+		t.Errorf("notes/issue count = %q/%d", plan.Notes, plan.IssueCount)
+	}
+
+	var b strings.Builder
+	if err := plan.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"3 suggested actions on 3 of 3 issues.",
+		"- [ ] Add labels docs: Documentation fix",
+		"- [ ] Close as a duplicate of #1: Same crash",
+		"- [ ] Set priority critical: Crashes on start",
+		"## Notes",
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("plan text missing %q:\n%s", line, b.String())
+		}
+	}
+}
+
+func TestTriage_StopsAfterMaxRounds(t *testing.T) {
+	src := &fakeSource{issues: []Issue{testIssue(1, "crash")}}
+	provider := &fakeProvider{}
+	for i := 0; i < maxTriageRounds+1; i++ {
+		provider.responses = append(provider.responses, &claude.Response{StopReason: claude.StopToolUse, Content: []claude.ContentBlock{
+			toolUse("a", ActionPriority, `{"issue":1,"level":"low","reason":"Minor"}`),
+		}})
+	}
+	plan, err := Triage(context.Background(), Options{Source: src, Provider: provider, Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(provider.requests) != maxTriageRounds {
+		t.Errorf("sent %d requests, want %d", len(provider.requests), maxTriageRounds)
+	}
+	if len(plan.Actions) != 1 {
+		t.Errorf("repeated suggestions for the same issue should replace each other, got %+v", plan.Actions)
+	}
+}
+
+func TestTriage_KeepsCallsBeforeCutOff(t *testing.T) {
+	src := &fakeSource{issues: []Issue{testIssue(1, "crash"), testIssue(2, "typo")}}
+	provider := &fakeProvider{responses: []*claude.Response{
+		{StopReason: claude.StopMaxTokens, Content: []claude.ContentBlock{
+			toolUse("a", ActionPriority, `{"issue":1,"level":"high","reason":"Crashes"}`),
+			toolUse("b", ActionLabels, `{"issue":2,"labels":["docs"],"reason":"Docs"}`),
+			{Type: "text", Text: "And #"},
+		}},
+	}}
+	plan, err := Triage(context.Background(), Options{Source: src, Provider: provider, Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(provider.requests) != 1 {
+		t.Errorf("sent %d requests, want 1", len(provider.requests))
+	}
+	if len(plan.Actions) != 2 || plan.Actions[0].Priority != "high" || plan.Actions[1].Labels[0] != "docs" {
+		t.Errorf("actions = %+v, want the complete calls ahead of the cut kept", plan.Actions)
+	}
+	if plan.Notes != "" {
+		t.Errorf("notes = %q, want none from a cut-off reply", plan.Notes)
+	}
+}
@@ -0,0 +1,226 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+// Price is what a model charges, in US dollars per million tokens.
+type Price struct {
+	Input      float64
+	Output     float64
+	CacheWrite float64
+	CacheRead  float64
+}
+
+// prices lists the list prices of known models by name prefix, most
+// specific first. Config files can add to and override them.
+var prices = []struct {
+	prefix string
+	price  Price
+}{
+	{"claude-opus-4-5", Price{Input: 5, Output: 25, CacheWrite: 6.25, CacheRead: 0.50}},
+	{"claude-opus-4", Price{Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.50}},
+	{"claude-3-opus", Price{Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.50}},
+	{"claude-sonnet-4", Price{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30}},
+	{"claude-3-7-sonnet", Price{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30}},
+	{"claude-3-5-sonnet", Price{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30}},
+	{"claude-haiku-4-5", Price{Input: 1, Output: 5, CacheWrite: 1.25, CacheRead: 0.10}},
+	{"claude-3-5-haiku", Price{Input: 0.80, Output: 4, CacheWrite: 1, CacheRead: 0.08}},
+	{"claude-3-haiku", Price{Input: 0.25, Output: 1.25, CacheWrite: 0.30, CacheRead: 0.03}},
+	{"gpt-4.1-nano", Price{Input: 0.10, Output: 0.40, CacheRead: 0.025}},
+	{"gpt-4.1-mini", Price{Input: 0.40, Output: 1.60, CacheRead: 0.10}},
+	{"gpt-4.1", Price{Input: 2, Output: 8, CacheRead: 0.50}},
+	{"gpt-4o-mini", Price{Input: 0.15, Output: 0.60, CacheRead: 0.075}},
+	{"gpt-4o", Price{Input: 2.50, Output: 10, CacheRead: 1.25}},
+	{"gpt-5-nano", Price{Input: 0.05, Output: 0.40, CacheRead: 0.005}},
+	{"gpt-5-mini", Price{Input: 0.25, Output: 2, CacheRead: 0.025}},
+	{"gpt-5", Price{Input: 1.25, Output: 10, CacheRead: 0.125}},
+}
+
+// PriceFor returns the price of model. The longest prefix of model in
+// overrides wins; failing that, the built-in table is used. It reports
+// false if neither knows the model.
+func PriceFor(model string, overrides map[string]Price) (Price, bool) {
+	best := -1
+	var price Price
+	for prefix, p := range overrides {
+		if strings.HasPrefix(model, prefix) && len(prefix) > best {
+			best, price = len(prefix), p
+		}
+	}
+	if best >= 0 {
+		return price, true
+	}
+	for _, p := range prices {
+		if strings.HasPrefix(model, p.prefix) {
+			return p.price, true
+		}
+	}
+	return Price{}, false
+}
+
+// Cost returns the cost of usage in US dollars.
+func (p Price) Cost(u claude.Usage) float64 {
+	return (float64(u.InputTokens)*p.Input +
+		float64(u.OutputTokens)*p.Output +
+		float64(u.CacheCreationInputTokens)*p.CacheWrite +
+		float64(u.CacheReadInputTokens)*p.CacheRead) / 1e6
+}
+
+// UsageReport is the token usage of a run and what it cost, as included in
+// JSON output.
+type UsageReport struct {
+	Requests int `json:"requests"`
+	claude.Usage
+	// EstimatedCost is in US dollars; it is missing if the model's price
+	// is unknown.
+	EstimatedCost *float64 `json:"estimated_cost_usd,omitempty"`
+}
+
+// Meter is a Provider that passes requests on to another, adding up their
+// token usage. With MaxCost set, it refuses any request that could take the
+// run's cost over it, judging by the estimated input and the request's
+// output limit.
+type Meter struct {
+	Provider Provider
+	Price    Price
+	// Priced is false when the model's price is unknown, in which case no
+	// cost is reported or enforced.
+	Priced bool
+	// MaxCost is the most the run may cost, in US dollars; zero means no
+	// limit.
+	MaxCost float64
+
+	mu       sync.Mutex
+	usage    claude.Usage
+	requests int
+	// reserved is the worst-case cost of the requests in flight.
+	reserved float64
+}
+
+// ErrCostLimit is returned for requests refused because of Meter.MaxCost.
+var ErrCostLimit = errors.New("cost limit reached")
+
+func (m *Meter) Send(ctx context.Context, req claude.Request) (*claude.Response, error) {
+	worst, err := m.reserve(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.Provider.Send(ctx, req)
+	m.record(resp, worst)
+	return resp, err
+}
+
+func (m *Meter) Stream(ctx context.Context, req claude.Request, onText func(string)) (*claude.Response, error) {
+	worst, err := m.reserve(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.Provider.Stream(ctx, req, onText)
+	m.record(resp, worst)
+	return resp, err
+}
+
+// CountTokens passes the count on if the wrapped provider can count, and
+// returns errors.ErrUnsupported otherwise.
+func (m *Meter) CountTokens(ctx context.Context, req claude.Request) (int, error) {
+	counter, ok := m.Provider.(TokenCounter)
+	if !ok {
+		return 0, errors.ErrUnsupported
+	}
+	return counter.CountTokens(ctx, req)
+}
+
+// SupportsPrefill reports whether the wrapped provider does.
+func (m *Meter) SupportsPrefill() bool {
+	return supportsPrefill(m.Provider)
+}
+
+// reserve checks req against the cost limit and sets aside its worst-case
+// cost while it runs, so concurrent requests can't overshoot together.
+// Input is priced as written to the cache if the request caches any of it,
+// since cache writes cost more than plain input.
+func (m *Meter) reserve(req claude.Request) (float64, error) {
+	if !m.Priced || m.MaxCost <= 0 {
+		return 0, nil
+	}
+	price := m.Price
+	if slices.ContainsFunc(req.Messages, claude.Message.Cached) {
+		price.Input = max(price.Input, price.CacheWrite)
+	}
+	worst := price.Cost(claude.Usage{InputTokens: requestTokens(req), OutputTokens: req.MaxTokens})
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	spent := m.Price.Cost(m.usage)
+	if spent+m.reserved+worst > m.MaxCost {
+		return 0, fmt.Errorf("%w: the next request could cost up to $%.4f, and $%.4f of the $%.2f limit is spent or committed",
+			ErrCostLimit, worst, spent+m.reserved, m.MaxCost)
+	}
+	m.reserved += worst
+	return worst, nil
+}
+
+func (m *Meter) record(resp *claude.Response, reserved float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reserved -= reserved
+	m.requests++
+	if resp != nil {
+		m.usage.Add(resp.Usage)
+	}
+}
+
+// Report returns the usage so far.
+func (m *Meter) Report() *UsageReport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r := &UsageReport{Requests: m.requests, Usage: m.usage}
+	if m.Priced {
+		cost := m.Price.Cost(m.usage)
+		r.EstimatedCost = &cost
+	}
+	return r
+}
+
+// String describes the usage for people, e.g. "3 requests, 12,345 input
+// tokens, 1,024 output tokens, estimated cost $0.0524".
+func (r *UsageReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d requests, %s input tokens", r.Requests, thousands(r.InputTokens))
+	if r.CacheCreationInputTokens > 0 || r.CacheReadInputTokens > 0 {
+		fmt.Fprintf(&b, " (plus %s written to and %s read from the cache)",
+			thousands(r.CacheCreationInputTokens), thousands(r.CacheReadInputTokens))
+	}
+	fmt.Fprintf(&b, ", %s output tokens", thousands(r.OutputTokens))
+	if r.EstimatedCost != nil {
+		fmt.Fprintf(&b, ", estimated cost $%.4f", *r.EstimatedCost)
+	} else {
+		b.WriteString(", cost unknown for this model")
+	}
+	return b.String()
+}
+
+func thousands(n int) string {
+	s := fmt.Sprint(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+// usageReport returns the usage so far if opts.Provider is metered, for
+// JSON output.
+func usageReport(opts Options) *UsageReport {
+	if m, ok := opts.Provider.(*Meter); ok {
+		return m.Report()
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/mrphil/gitissuesum/internal/claude"
+)
+
+func TestPriceFor(t *testing.T) {
+	if p, ok := PriceFor("claude-sonnet-4-20250514", nil); !ok || p.Input != 3 || p.Output != 15 {
+		t.Errorf("claude-sonnet-4 = %+v, %v, want $3/$15", p, ok)
+	}
+	if p, _ := PriceFor("gpt-4o-mini-2024-07-18", nil); p.Input != 0.15 {
+		t.Errorf("gpt-4o-mini input = %v, want the mini price rather than gpt-4o's", p.Input)
+	}
+	if _, ok := PriceFor("llama3", nil); ok {
+		t.Error("unknown model should have no price")
+	}
+
+	overrides := map[string]Price{
+		"llama":   {Input: 1},
+		"llama3":  {Input: 2},
+		"claude-": {Input: 9},
+	}
+	if p, ok := PriceFor("llama3:8b", overrides); !ok || p.Input != 2 {
+		t.Errorf("llama3:8b = %+v, %v, want the longest override prefix", p, ok)
+	}
+	if p, _ := PriceFor("claude-sonnet-4", overrides); p.Input != 9 {
+		t.Errorf("override should beat the built-in price, got %+v", p)
+	}
+}
+
+func TestPriceCost(t *testing.T) {
+	p := Price{Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30}
+	got := p.Cost(claude.Usage{InputTokens: 1_000_000, OutputTokens: 100_000, CacheCreationInputTokens: 200_000, CacheReadInputTokens: 1_000_000})
+	want := 3 + 1.5 + 0.75 + 0.30
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("Cost() = %v, want %v", got, want)
+	}
+}
+
+func TestMeter_AddsUpUsage(t *testing.T) {
+	inner := &fakeProvider{usage: claude.Usage{InputTokens: 1000, OutputTokens: 200, CacheReadInputTokens: 50}}
+	m := &Meter{Provider: inner, Price: Price{Input: 3, Output: 15}, Priced: true}
+	opts := Options{Provider: m, Model: "m"}
+
+	if _, err := complete(context.Background(), opts, "one"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := completeStream(context.Background(), opts, "two", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	r := usageReport(opts)
+	if r.Requests != 2 || r.InputTokens != 2000 || r.OutputTokens != 400 || r.CacheReadInputTokens != 100 {
+		t.Errorf("report = %+v, want 2 requests, 2000 in, 400 out, 100 cached", r)
+	}
+	if r.EstimatedCost == nil || math.Abs(*r.EstimatedCost-0.012) > 1e-9 {
+		t.Errorf("estimated cost = %v, want 0.012", r.EstimatedCost)
+	}
+	if s := r.String(); !strings.Contains(s, "2,000 input tokens") || !strings.Contains(s, "$0.0120") {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestMeter_Unpriced(t *testing.T) {
+	m := &Meter{Provider: &fakeProvider{}, MaxCost: 0.01}
+	if _, err := m.Send(context.Background(), claude.NewRequest("local", "hi")); err != nil {
+		t.Fatalf("an unpriced model can't be held to a limit, got %v", err)
+	}
+	if r := m.Report(); r.EstimatedCost != nil || !strings.Contains(r.String(), "cost unknown") {
+		t.Errorf("report = %+v, want no cost", r)
+	}
+}
+
+func TestMeter_MaxCost(t *testing.T) {
+	inner := &fakeProvider{usage: claude.Usage{InputTokens: 1000, OutputTokens: 1000}}
+	// Each request may cost up to 4096 output tokens at $10/M, about $0.04,
+	// and actually costs $0.011.
+	m := &Meter{Provider: inner, Price: Price{Input: 1, Output: 10}, Priced: true, MaxCost: 0.05}
+
+	if _, err := m.Send(context.Background(), claude.NewRequest("m", "prompt")); err != nil {
+		t.Fatalf("first request is within the limit, got %v", err)
+	}
+	_, err := m.Send(context.Background(), claude.NewRequest("m", "prompt"))
+	if !errors.Is(err, ErrCostLimit) {
+		t.Fatalf("second request could exceed the limit, got %v", err)
+	}
+	if len(inner.requests) != 1 {
+		t.Errorf("sent %d requests, want the second refused before sending", len(inner.requests))
+	}
+}
+
+func TestMeter_MaxCostCachedInput(t *testing.T) {
+	inner := &fakeProvider{usage: claude.Usage{InputTokens: 1000}}
+	// 40,000 input tokens cost $0.04 as plain input but $0.08 written to
+	// the cache, which the limit is between.
+	price := Price{Input: 1, Output: 1, CacheWrite: 2}
+	prompt := strings.Repeat("x", 160000)
+	req := NewRequest(Options{Model: "m", Params: Params{MaxTokens: 1}}, prompt)
+	cached := newChatRequest(Options{Model: "m", Params: Params{MaxTokens: 1}}, []claude.Message{claude.CachedMessage("user", prompt, "")})
+
+	m := &Meter{Provider: inner, Price: price, Priced: true, MaxCost: 0.06}
+	if _, err := m.Send(context.Background(), req); err != nil {
+		t.Fatalf("uncached request is within the limit, got %v", err)
+	}
+	m = &Meter{Provider: inner, Price: price, Priced: true, MaxCost: 0.06}
+	if _, err := m.Send(context.Background(), cached); !errors.Is(err, ErrCostLimit) {
+		t.Errorf("cached request could exceed the limit at the cache write price, got %v", err)
+	}
+}
+
+func TestCountTokens_MeterWithoutCounter(t *testing.T) {
+	opts := Options{Provider: &Meter{Provider: &fakeProvider{}}, Model: "m", CountTokens: true}
+	n, err := countTokens(context.Background(), opts, strings.Repeat("x", 400))
+	if err != nil {
+		t.Fatalf("countTokens() error: %v", err)
+	}
+	if n != estimateTokens(strings.Repeat("x", 400)) {
+		t.Errorf("countTokens() = %d, want the estimate", n)
+	}
+}
@@ -0,0 +1,20 @@
+// Package truncate shortens text for prompts and reports.
+package truncate
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// String trims the space around s and shortens it to at most maxLen bytes,
+// marking the cut with "...". The cut never splits a UTF-8 character.
+func String(s string, maxLen int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= maxLen {
+		return s
+	}
+	for maxLen > 0 && !utf8.RuneStart(s[maxLen]) {
+		maxLen--
+	}
+	return s[:maxLen] + "..."
+}
@@ -0,0 +1,31 @@
+package truncate
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		s      string
+		maxLen int
+		want   string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello world", 5, "hello..."},
+		{"  hi  ", 10, "hi"},
+		// "é" is two bytes; cutting at 2 would split it.
+		{"héllo", 2, "h..."},
+		{"日本語", 4, "日..."},
+	}
+	for _, tt := range tests {
+		got := String(tt.s, tt.maxLen)
+		if got != tt.want {
+			t.Errorf("String(%q, %d) = %q, want %q", tt.s, tt.maxLen, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("String(%q, %d) = %q, which is not valid UTF-8", tt.s, tt.maxLen, got)
+		}
+	}
+}